@@ -8,6 +8,7 @@ import (
 	"io"
 	"strings"
 	"testing"
+	"time"
 )
 
 func BenchmarkLogger_Info(b *testing.B) {
@@ -23,6 +24,32 @@ func BenchmarkLogger_Info(b *testing.B) {
 
 }
 
+func BenchmarkLogger_InfoF(b *testing.B) {
+	log := logger.NewWithWriter(logger.LvlInfo, io.Discard)
+	longstring := makeString(50)
+	alloc := testing.AllocsPerRun(b.N, func() {
+		log.InfoF("Lorem \"ipsum\"",
+			logger.String("Key", longstring),
+			logger.Int64("K2", 34875634),
+			logger.Float64("K3", 1.25))
+	})
+	b.Logf("Allocations:  %f", alloc)
+}
+
+func BenchmarkLogger_InfoF_CoarseClock(b *testing.B) {
+	clock := logger.NewCoarseClock(time.Millisecond, logger.TimeRFC3339Micro, nil)
+	defer clock.Stop()
+	log := logger.NewWithWriter(logger.LvlInfo, io.Discard, logger.WithClock(clock))
+	longstring := makeString(50)
+	alloc := testing.AllocsPerRun(b.N, func() {
+		log.InfoF("Lorem \"ipsum\"",
+			logger.String("Key", longstring),
+			logger.Int64("K2", 34875634),
+			logger.Float64("K3", 1.25))
+	})
+	b.Logf("Allocations:  %f", alloc)
+}
+
 func BenchmarkLogger_zap_Infow(b *testing.B) {
 	encoderCfg := zap.NewProductionEncoderConfig()
 	encoderCfg.TimeKey = "timestamp"
@@ -41,6 +68,24 @@ func BenchmarkLogger_zap_Infow(b *testing.B) {
 	b.Logf("Allocations:  %f", alloc)
 }
 
+func BenchmarkLogger_zap_Info(b *testing.B) {
+	encoderCfg := zap.NewProductionEncoderConfig()
+	encoderCfg.TimeKey = "timestamp"
+	encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(encoderCfg), zapcore.AddSync(io.Discard), zap.InfoLevel)
+	logger := zap.New(core)
+	defer logger.Sync()
+	longstring := makeString(50)
+	alloc := testing.AllocsPerRun(b.N, func() {
+		logger.Info("Lorem \"ipsum\"",
+			zap.String("Key", longstring),
+			zap.Int64("K2", 34875634),
+			zap.Float64("K3", 1.25))
+	})
+	b.Logf("Allocations:  %f", alloc)
+}
+
 func BenchmarkLogger_zerolog_Info(b *testing.B) {
 	log := zerolog.New(io.Discard).With().Timestamp().Logger()
 	longstring := makeString(50)
@@ -50,6 +95,43 @@ func BenchmarkLogger_zerolog_Info(b *testing.B) {
 	b.Logf("Allocations:  %f", alloc)
 }
 
+func BenchmarkLogger_InfoEvent(b *testing.B) {
+	log := logger.NewWithWriter(logger.LvlInfo, io.Discard)
+	longstring := makeString(50)
+	alloc := testing.AllocsPerRun(b.N, func() {
+		log.InfoEvent().Str("Key", longstring).Int("K2", 34875634).Float64("K3", 1.25).Msg("Lorem \"ipsum\"")
+	})
+	b.Logf("Allocations:  %f", alloc)
+}
+
+func BenchmarkLogger_DebugEvent_Disabled(b *testing.B) {
+	log := logger.NewWithWriter(logger.LvlInfo, io.Discard)
+	longstring := makeString(50)
+	alloc := testing.AllocsPerRun(b.N, func() {
+		log.DebugEvent().Str("Key", longstring).Int("K2", 34875634).Float64("K3", 1.25).Msg("Lorem \"ipsum\"")
+	})
+	b.Logf("Allocations:  %f", alloc)
+}
+
+func BenchmarkLogger_Named_Debug_Disabled(b *testing.B) {
+	levels, _ := logger.NewLevelTable("payment=DEBUG,*=INFO")
+	log := logger.NewWithWriter(logger.LvlInfo, io.Discard, logger.WithLevelTable(levels)).Named("shipping.client")
+	longstring := makeString(50)
+	alloc := testing.AllocsPerRun(b.N, func() {
+		log.Debug("Lorem \"ipsum\"", "Key", longstring, "K2", 34875634, "K3", 1.25)
+	})
+	b.Logf("Allocations:  %f", alloc)
+}
+
+func BenchmarkLogger_zerolog_Debug_Disabled(b *testing.B) {
+	log := zerolog.New(io.Discard).With().Timestamp().Logger().Level(zerolog.InfoLevel)
+	longstring := makeString(50)
+	alloc := testing.AllocsPerRun(b.N, func() {
+		log.Debug().Str("Key", longstring).Int("K2", 34875634).Float64("K3", 1.25).Msg("Lorem \"ipsum\"")
+	})
+	b.Logf("Allocations:  %f", alloc)
+}
+
 func makeString(length int) string {
 	var sb strings.Builder
 	for i := 0; i < length; i++ {
@@ -19,3 +19,48 @@ func BenchmarkLogger_FormatTimeStdLib(b *testing.B) {
 		now.String()
 	}
 }
+
+func benchmarkAppendLogTime(b *testing.B, format logger.TimeFormat, loc *time.Location) {
+	now := time.Now()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var buf [40]byte
+		logger.AppendLogTime(buf[:0], now, format, loc)
+	}
+}
+
+func BenchmarkLogger_AppendLogTime_RFC3339Milli(b *testing.B) {
+	benchmarkAppendLogTime(b, logger.TimeRFC3339Milli, nil)
+}
+
+func BenchmarkLogger_AppendLogTime_RFC3339Micro(b *testing.B) {
+	benchmarkAppendLogTime(b, logger.TimeRFC3339Micro, nil)
+}
+
+func BenchmarkLogger_AppendLogTime_RFC3339Nano(b *testing.B) {
+	benchmarkAppendLogTime(b, logger.TimeRFC3339Nano, nil)
+}
+
+func BenchmarkLogger_AppendLogTime_RFC3339Local(b *testing.B) {
+	benchmarkAppendLogTime(b, logger.TimeRFC3339Micro, time.Local)
+}
+
+func BenchmarkLogger_AppendLogTime_Unix(b *testing.B) {
+	benchmarkAppendLogTime(b, logger.TimeUnix, nil)
+}
+
+func BenchmarkLogger_AppendLogTime_UnixMilli(b *testing.B) {
+	benchmarkAppendLogTime(b, logger.TimeUnixMilli, nil)
+}
+
+func BenchmarkLogger_AppendLogTime_UnixNano(b *testing.B) {
+	benchmarkAppendLogTime(b, logger.TimeUnixNano, nil)
+}
+
+func BenchmarkLogger_FormatTimeStdLib_RFC3339Nano(b *testing.B) {
+	now := time.Now()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		now.UTC().Format(time.RFC3339Nano)
+	}
+}
@@ -0,0 +1,120 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Clock returns the time of records.
+type Clock interface {
+	Now() time.Time
+}
+
+// WithClock sets the clock for record timestamps and sampling ticks. Default is time.Now.
+func WithClock(c Clock) Option {
+	return func(l *instance) {
+		l.clock = c
+	}
+}
+
+// clockNow returns the time of c, or time.Now if c is nil.
+func clockNow(c Clock) time.Time {
+	if c != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+// FakeClock is a Clock for tests that only changes when it is told to.
+type FakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+// NewFakeClock returns a FakeClock set to now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the time the clock was set to.
+func (c *FakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+// Set sets the clock to now.
+func (c *FakeClock) Set(now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = now
+}
+
+// Add advances the clock by d.
+func (c *FakeClock) Add(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// CoarseClock is a Clock that reads the time only once per resolution, in a background
+// goroutine. It also keeps the formatted timestamp, so loggers with the same TimeFormat
+// and location write it without formatting. Timestamps of records can be up to one
+// resolution late. Call Stop if the clock is not used anymore.
+type CoarseClock struct {
+	format    TimeFormat
+	loc       *time.Location
+	now       atomic.Value // time.Time
+	timestamp atomic.Value // string
+	stop      chan struct{}
+	stopOnce  sync.Once
+}
+
+// NewCoarseClock starts a clock that is updated every resolution, e.g. time.Millisecond,
+// and keeps the timestamp formatted by format and loc.
+func NewCoarseClock(resolution time.Duration, format TimeFormat, loc *time.Location) *CoarseClock {
+	c := &CoarseClock{
+		format: format,
+		loc:    loc,
+		stop:   make(chan struct{}),
+	}
+	c.update(time.Now())
+
+	go func() {
+		ticker := time.NewTicker(resolution)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.stop:
+				return
+			case now := <-ticker.C:
+				c.update(now)
+			}
+		}
+	}()
+	return c
+}
+
+func (c *CoarseClock) update(now time.Time) {
+	c.now.Store(now)
+	c.timestamp.Store(string(AppendLogTime(nil, now, c.format, c.loc)))
+}
+
+// Now returns the time of the last update.
+func (c *CoarseClock) Now() time.Time {
+	return c.now.Load().(time.Time)
+}
+
+// Stop stops updating the clock.
+func (c *CoarseClock) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+}
+
+// formattedTimestamp returns the timestamp of the last update, formatted by the format and
+// location of the clock.
+func (c *CoarseClock) formattedTimestamp() string {
+	return c.timestamp.Load().(string)
+}
@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithClock_FakeClock(t *testing.T) {
+	clock := NewFakeClock(time.Date(2022, 2, 1, 13, 1, 2, 123456789, time.UTC))
+	out := &bytes.Buffer{}
+	logger := NewWithWriter(LvlInfo, out, WithClock(clock))
+
+	logger.Info("first")
+	clock.Add(1500 * time.Millisecond)
+	logger.Named("child").Info("second")
+	clock.Set(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+	logger.Info("third")
+
+	expected := `{"ts": "2022-02-01T13:01:02.123456Z", "level": "INFO", "message": "first"}
+{"ts": "2022-02-01T13:01:03.623456Z", "level": "INFO", "message": "second", "logger": "child"}
+{"ts": "2023-01-01T00:00:00.000000Z", "level": "INFO", "message": "third"}
+`
+	if out.String() != expected {
+		t.Errorf("Output is incorrect, Expected\n%s\nActual\n%s", expected, out.String())
+	}
+}
+
+func TestCoarseClock(t *testing.T) {
+	clock := NewCoarseClock(time.Millisecond, TimeUnixMilli, nil)
+	defer clock.Stop()
+
+	first := clock.Now()
+	deadline := time.Now().Add(5 * time.Second)
+	for !clock.Now().After(first) {
+		if time.Now().After(deadline) {
+			t.Fatal("CoarseClock was not updated")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	out := &bytes.Buffer{}
+	NewWithWriter(LvlInfo, out, WithClock(clock), WithTimeFormat(TimeUnixMilli)).Info("msg")
+	if !strings.HasPrefix(out.String(), `{"ts": 1`) || !strings.HasSuffix(out.String(), `, "level": "INFO", "message": "msg"}`+"\n") {
+		t.Errorf("Record is incorrect: %s", out.String())
+	}
+
+	clock.Stop()
+	clock.Stop()
+}
+
+func TestCoarseClock_OtherFormat(t *testing.T) {
+	clock := NewCoarseClock(time.Hour, TimeUnix, nil)
+	defer clock.Stop()
+
+	// The logger formats the time of the clock itself.
+	out := &bytes.Buffer{}
+	logger := NewWithWriter(LvlInfo, out, WithClock(clock))
+	if logger.coarseClock != nil {
+		t.Errorf("Timestamps of other formats must not be used")
+	}
+	logger.Info("msg")
+
+	ts := FormatLogTime(clock.Now())
+	if !strings.HasPrefix(out.String(), `{"ts": "`+string(ts[:])+`"`) {
+		t.Errorf("Record is incorrect: %s", out.String())
+	}
+}
+
+func TestCoarseClock_ZeroAlloc(t *testing.T) {
+	clock := NewCoarseClock(time.Millisecond, TimeRFC3339Micro, nil)
+	defer clock.Stop()
+	logger := NewWithWriter(LvlInfo, io.Discard, WithClock(clock))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.InfoF("msg", Int("a", 1))
+	})
+	if allocs != 0 {
+		t.Errorf("Expected 0 allocations, Actual %f", allocs)
+	}
+}
@@ -0,0 +1,176 @@
+package logger
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Config holds the settings that are read from environment variables or command line flags.
+// The values are only validated by New, so every service reports configuration mistakes the
+// same way.
+type Config struct {
+	// Level is the name of the level, e.g. INFO.
+	Level string
+	// Format of the records: json, logfmt or console.
+	Format string
+	// Output is stdout, stderr or the path of a file the records are appended to.
+	Output string
+	// Caller is the minimum level for caller info, or off.
+	Caller string
+	// StackTrace is the minimum level for stack traces, or off.
+	StackTrace string
+	// Fields are written in every record, e.g. "service=payment,env=prod".
+	Fields string
+}
+
+// DefaultConfig returns the configuration of a logger created by New(LvlInfo).
+func DefaultConfig() Config {
+	return Config{
+		Level:      LvlInfo,
+		Format:     "json",
+		Output:     "stdout",
+		Caller:     LvlWarn,
+		StackTrace: LvlFatal,
+	}
+}
+
+// ConfigFromEnv returns the default configuration, overridden by the environment variables
+// <prefix>_LEVEL, <prefix>_FORMAT, <prefix>_OUTPUT, <prefix>_CALLER, <prefix>_STACKTRACE
+// and <prefix>_FIELDS.
+func ConfigFromEnv(prefix string) Config {
+	prefix = strings.TrimSuffix(prefix, "_") + "_"
+	c := DefaultConfig()
+	for suffix, value := range c.values() {
+		if env, ok := os.LookupEnv(prefix + suffix); ok {
+			*value = env
+		}
+	}
+	return c
+}
+
+// NewFromEnv creates a logger configured by environment variables, see ConfigFromEnv.
+// NewFromEnv("LOG") reads LOG_LEVEL, LOG_FIELDS etc. Options are applied after the
+// configuration.
+func NewFromEnv(prefix string, opts ...Option) (Logger, error) {
+	c := ConfigFromEnv(prefix)
+	return c.New(opts...)
+}
+
+// RegisterFlags registers the flags -log-level, -log-format, -log-output, -log-caller,
+// -log-stacktrace and -log-fields. Call New on the result after the flags were parsed:
+//
+//	logConfig := logger.RegisterFlags(flag.CommandLine)
+//	flag.Parse()
+//	log, err := logConfig.New()
+func RegisterFlags(fs *flag.FlagSet) *Config {
+	c := DefaultConfig()
+	fs.StringVar(&c.Level, "log-level", c.Level, "log level: TRACE, DEBUG, INFO, WARN, ERROR or a custom level")
+	fs.StringVar(&c.Format, "log-format", c.Format, "log format: json, logfmt or console")
+	fs.StringVar(&c.Output, "log-output", c.Output, "log output: stdout, stderr or a file path")
+	fs.StringVar(&c.Caller, "log-caller", c.Caller, "minimum level for caller info, or off")
+	fs.StringVar(&c.StackTrace, "log-stacktrace", c.StackTrace, "minimum level for stack traces, or off")
+	fs.StringVar(&c.Fields, "log-fields", c.Fields, "fields written in every record, e.g. service=payment,env=prod")
+	return &c
+}
+
+// values returns the settings by the suffix of their environment variable.
+func (c *Config) values() map[string]*string {
+	return map[string]*string{
+		"LEVEL":      &c.Level,
+		"FORMAT":     &c.Format,
+		"OUTPUT":     &c.Output,
+		"CALLER":     &c.Caller,
+		"STACKTRACE": &c.StackTrace,
+		"FIELDS":     &c.Fields,
+	}
+}
+
+// New validates the configuration and creates a logger. Options are applied after the
+// configuration.
+func (c *Config) New(opts ...Option) (Logger, error) {
+	configOpts, err := c.options()
+	if err != nil {
+		return nil, err
+	}
+
+	writer, err := openOutput(c.Output)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithWriter(c.Level, writer, append(configOpts, opts...)...), nil
+}
+
+// options validates everything except the output and returns the matching options.
+func (c *Config) options() ([]Option, error) {
+	if _, err := ParseLevel(c.Level); err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", c.Level, err)
+	}
+
+	format, err := ParseFormat(c.Format)
+	if err != nil {
+		return nil, err
+	}
+	opts := []Option{WithFormat(format)}
+
+	caller, err := parseLevelOrOff(c.Caller)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log caller level %q: %w", c.Caller, err)
+	}
+	opts = append(opts, WithCallerLevel(caller))
+
+	stackTrace, err := parseLevelOrOff(c.StackTrace)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log stacktrace level %q: %w", c.StackTrace, err)
+	}
+	opts = append(opts, WithStackTraceLevel(stackTrace))
+
+	fields, err := parseFields(c.Fields)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) > 0 {
+		opts = append(opts, WithFields(fields...))
+	}
+	return opts, nil
+}
+
+func parseLevelOrOff(s string) (Level, error) {
+	switch strings.ToLower(s) {
+	case "off", "none", "false":
+		return levelOff, nil
+	}
+	return ParseLevel(s)
+}
+
+// parseFields parses comma separated key=value pairs.
+func parseFields(s string) ([]Field, error) {
+	var fields []Field
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid log field %q, expected key=value", pair)
+		}
+		fields = append(fields, String(key, strings.TrimSpace(value)))
+	}
+	return fields, nil
+}
+
+func openOutput(output string) (*os.File, error) {
+	switch output {
+	case "", "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	}
+	f, err := os.OpenFile(output, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log output: %w", err)
+	}
+	return f, nil
+}
@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewFromEnv(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "app.log")
+	t.Setenv("APP_LOG_LEVEL", "debug")
+	t.Setenv("APP_LOG_OUTPUT", output)
+	t.Setenv("APP_LOG_CALLER", "off")
+	t.Setenv("APP_LOG_STACKTRACE", "error")
+	t.Setenv("APP_LOG_FIELDS", "service=payment, env=prod")
+
+	logger, err := NewFromEnv("APP_LOG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Debug("Started", "port", 8080)
+	logger.Warn("Slow")
+	logger.Error("Failed")
+	logger.(*instance).closeWriter()
+
+	out, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 records, Actual %d: %s", len(lines), out)
+	}
+	if !strings.HasSuffix(lines[0], `"level": "DEBUG", "message": "Started", "service": "payment", "env": "prod", "port": 8080}`) {
+		t.Errorf("Record is incorrect: %s", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], `"message": "Slow", "service": "payment", "env": "prod"}`) {
+		t.Errorf("Record should not have caller info: %s", lines[1])
+	}
+	if !strings.Contains(lines[2], `"stacktrace": `) || strings.Contains(lines[2], `"caller_func"`) {
+		t.Errorf("Record should have a stacktrace only: %s", lines[2])
+	}
+}
+
+func TestNewFromEnv_Defaults(t *testing.T) {
+	logger, err := NewFromEnv("UNSET_LOG_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := logger.(*instance)
+	if l.level != LevelInfo || l.writer != os.Stdout || l.callerLevel != LevelWarn || l.stackTraceLevel != LevelFatal || l.fields != nil {
+		t.Errorf("Logger does not use the defaults: %+v", l)
+	}
+}
+
+func TestConfig_New_Errors(t *testing.T) {
+	tests := map[string]func(c *Config){
+		`invalid log level "LOUD"`:                                   func(c *Config) { c.Level = "LOUD" },
+		`invalid log format "xml", expected json, logfmt or console`: func(c *Config) { c.Format = "xml" },
+		`invalid log output`:                                         func(c *Config) { c.Output = filepath.Join(t.TempDir(), "missing", "app.log") },
+		`invalid log caller level "sometimes"`:                       func(c *Config) { c.Caller = "sometimes" },
+		`invalid log stacktrace level "x"`:                           func(c *Config) { c.StackTrace = "x" },
+		`invalid log field "env", expected key=value`:                func(c *Config) { c.Fields = "service=payment,env" },
+		`invalid log field "=prod", expected key=value`:              func(c *Config) { c.Fields = "=prod" },
+	}
+	for expected, modify := range tests {
+		c := DefaultConfig()
+		modify(&c)
+		logger, err := c.New()
+		if err == nil || !strings.HasPrefix(err.Error(), expected) {
+			t.Errorf("Error is incorrect, Expected %s, Actual %v", expected, err)
+		}
+		if logger != nil {
+			t.Errorf("Logger should be nil on error")
+		}
+	}
+}
+
+func TestRegisterFlags(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	config := RegisterFlags(flags)
+	err := flags.Parse([]string{"-log-level", "trace", "-log-output", "stderr", "-log-caller", "ERROR", "-log-fields", "service=payment"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger, err := config.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := logger.(*instance)
+	if l.level != LevelTrace || l.writer != os.Stderr || l.callerLevel != LevelError || len(l.fields) != 1 || l.fields[0].Key != "service" {
+		t.Errorf("Logger does not use the flags: %+v", l)
+	}
+}
+
+func TestNewFromEnv_Format(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "app.log")
+	t.Setenv("APP_LOG_FORMAT", "logfmt")
+	t.Setenv("APP_LOG_OUTPUT", output)
+	t.Setenv("APP_LOG_FIELDS", "service=payment")
+
+	logger, err := NewFromEnv("APP_LOG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("Started", "port", 8080)
+	logger.(*instance).closeWriter()
+
+	out, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(out), " level=INFO message=Started service=payment port=8080\n") {
+		t.Errorf("Record is incorrect: %s", out)
+	}
+}
@@ -0,0 +1,313 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// fileConfig is the content of a configuration file, see WatchConfigFile.
+type fileConfig struct {
+	Level     string            `json:"level"`
+	Levels    map[string]string `json:"levels"`
+	Sinks     []string          `json:"sinks"`
+	Sampling  *fileSampling     `json:"sampling"`
+	Redaction *fileRedaction    `json:"redaction"`
+}
+
+type fileSampling struct {
+	First      int    `json:"first"`
+	Thereafter int    `json:"thereafter"`
+	Tick       string `json:"tick"`
+}
+
+type fileRedaction struct {
+	Keys     []string `json:"keys"`
+	Patterns []string `json:"patterns"`
+	HMACKey  string   `json:"hmac_key"`
+}
+
+var patternsByName = map[string]ValuePattern{
+	PatternCreditCard.Name: PatternCreditCard,
+	PatternJWT.Name:        PatternJWT,
+	PatternEmail.Name:      PatternEmail,
+	PatternIBAN.Name:       PatternIBAN,
+}
+
+// loadedConfig is a validated configuration file.
+type loadedConfig struct {
+	levels   map[string]Level
+	writer   io.Writer
+	closers  []io.Closer
+	sampling Sampling
+	redactor *Redactor
+}
+
+// ConfigWatcher applies changes of a configuration file to a logger, see WatchConfigFile.
+type ConfigWatcher struct {
+	path     string
+	logger   *instance
+	writer   *switchWriter
+	levels   *LevelTable
+	sampler  *sampler
+	redactor *Redactor
+
+	reloadMutex sync.Mutex
+	modTime     time.Time
+	size        int64
+
+	signals chan os.Signal
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// WatchConfigFile creates a logger that is configured by a JSON file:
+//
+//	{
+//	  "level": "INFO",
+//	  "levels": {"payment": "DEBUG", "payment.client": "TRACE"},
+//	  "sinks": ["stdout", "/var/log/app.log"],
+//	  "sampling": {"first": 100, "thereafter": 10, "tick": "1s"},
+//	  "redaction": {"keys": ["password"], "patterns": ["credit_card", "email"], "hmac_key": "..."}
+//	}
+//
+// All settings are optional, the default sink is stdout. The levels are the overrides of
+// the LevelTable of the logger, see Named. The patterns are the names of the predefined
+// ValuePatterns.
+//
+// The file is reloaded on SIGHUP (on Unix) and, if interval is not 0, when its modification
+// time or size changed. A file that is invalid as a whole keeps the previous configuration,
+// the error is logged by the logger. Options must not replace the level table, redactor or
+// sampling, they would not be reloaded.
+func WatchConfigFile(path string, interval time.Duration, opts ...Option) (Logger, *ConfigWatcher, error) {
+	config, err := loadConfigFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	w := &ConfigWatcher{
+		path:     path,
+		writer:   &switchWriter{},
+		levels:   &LevelTable{},
+		sampler:  newSampler(Sampling{}),
+		redactor: NewRedactor(RedactorConfig{}),
+		signals:  make(chan os.Signal, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	w.modTime, w.size = w.stat()
+
+	watcherOpts := []Option{
+		WithLevelTable(w.levels),
+		WithRedactor(w.redactor),
+		func(l *instance) {
+			l.sampler = w.sampler
+		},
+	}
+	w.logger = NewWithWriter(LvlInfo, w.writer, append(watcherOpts, opts...)...)
+	w.apply(config)
+
+	notifyReload(w.signals)
+	go w.watch(interval)
+	return w.logger, w, nil
+}
+
+// Reload reads the configuration file and applies it. If the file is invalid, the previous
+// configuration is kept and the error is logged and returned.
+func (w *ConfigWatcher) Reload() error {
+	w.reloadMutex.Lock()
+	defer w.reloadMutex.Unlock()
+
+	w.modTime, w.size = w.stat()
+	config, err := loadConfigFile(w.path)
+	if err != nil {
+		w.logger.ErrorF("Invalid log configuration, keeping the previous one", String("path", w.path), Err(err))
+		return err
+	}
+	w.apply(config)
+	return nil
+}
+
+// Close stops watching the file. The logger keeps the last configuration.
+func (w *ConfigWatcher) Close() error {
+	signal.Stop(w.signals)
+	close(w.stop)
+	<-w.done
+	return nil
+}
+
+func (w *ConfigWatcher) watch(interval time.Duration) {
+	defer close(w.done)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-w.signals:
+			_ = w.Reload()
+		case <-tick:
+			w.reloadMutex.Lock()
+			modTime, size := w.stat()
+			changed := !modTime.Equal(w.modTime) || size != w.size
+			w.reloadMutex.Unlock()
+			if changed {
+				_ = w.Reload()
+			}
+		}
+	}
+}
+
+// stat returns the modification time and size of the file, or zero values if it cannot
+// be read, e.g. while it is replaced.
+func (w *ConfigWatcher) stat() (time.Time, int64) {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return time.Time{}, 0
+	}
+	return info.ModTime(), info.Size()
+}
+
+// apply replaces the configuration of the logger. Writer and redactor are only used while
+// the logger is locked, so they are replaced under the lock.
+func (w *ConfigWatcher) apply(config *loadedConfig) {
+	w.levels.replace(config.levels)
+	w.sampler.set(config.sampling)
+
+	w.logger.mutex.Lock()
+	oldClosers := w.writer.closers
+	w.writer.writer = config.writer
+	w.writer.closers = config.closers
+	*w.redactor = *config.redactor
+	w.logger.mutex.Unlock()
+
+	for _, c := range oldClosers {
+		_ = c.Close()
+	}
+}
+
+// loadConfigFile reads and validates a configuration file. Nothing is changed if it fails.
+func loadConfigFile(path string) (*loadedConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read log configuration: %w", err)
+	}
+
+	var file fileConfig
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid log configuration %s: %w", path, err)
+	}
+
+	config, err := file.validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid log configuration %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// validate converts the file content. Sinks are opened last, so they only have to be
+// closed if opening another sink fails.
+func (f *fileConfig) validate() (*loadedConfig, error) {
+	config := &loadedConfig{levels: map[string]Level{}}
+
+	rootLevel := LevelInfo
+	if f.Level != "" {
+		level, err := ParseLevel(f.Level)
+		if err != nil {
+			return nil, fmt.Errorf("level: %w", err)
+		}
+		rootLevel = level
+	}
+	config.levels["*"] = rootLevel
+	for name, levelName := range f.Levels {
+		level, err := ParseLevel(levelName)
+		if err != nil {
+			return nil, fmt.Errorf("levels.%s: %w", name, err)
+		}
+		config.levels[name] = level
+	}
+
+	if f.Sampling != nil {
+		s := f.Sampling
+		if s.First < 0 || s.Thereafter < 0 {
+			return nil, fmt.Errorf("sampling: first and thereafter must not be negative")
+		}
+		config.sampling = Sampling{First: s.First, Thereafter: s.Thereafter}
+		if s.Tick != "" {
+			// Without tick, the sampler uses its default.
+			tick, err := time.ParseDuration(s.Tick)
+			if err != nil || tick <= 0 {
+				return nil, fmt.Errorf("sampling.tick: invalid duration %q", s.Tick)
+			}
+			config.sampling.Tick = tick
+		}
+	}
+
+	redaction := RedactorConfig{}
+	if f.Redaction != nil {
+		redaction.Keys = f.Redaction.Keys
+		for _, name := range f.Redaction.Patterns {
+			pattern, ok := patternsByName[name]
+			if !ok {
+				return nil, fmt.Errorf("redaction.patterns: unknown pattern %q", name)
+			}
+			redaction.Patterns = append(redaction.Patterns, pattern)
+		}
+		if f.Redaction.HMACKey != "" {
+			redaction.HMACKey = []byte(f.Redaction.HMACKey)
+		}
+	}
+	config.redactor = NewRedactor(redaction)
+
+	sinks := f.Sinks
+	if len(sinks) == 0 {
+		sinks = []string{"stdout"}
+	}
+	writers := make([]io.Writer, 0, len(sinks))
+	for _, sink := range sinks {
+		file, err := openOutput(sink)
+		if err != nil {
+			for _, c := range config.closers {
+				_ = c.Close()
+			}
+			return nil, fmt.Errorf("sinks: %w", err)
+		}
+		writers = append(writers, file)
+		if file != os.Stdout && file != os.Stderr {
+			config.closers = append(config.closers, file)
+		}
+	}
+	config.writer = writers[0]
+	if len(writers) > 1 {
+		config.writer = MultiWriter(writers...)
+	}
+	return config, nil
+}
+
+// switchWriter forwards to the sinks of the current configuration. It is only used while
+// the logger is locked.
+type switchWriter struct {
+	writer  io.Writer
+	closers []io.Closer
+}
+
+func (w *switchWriter) Write(p []byte) (int, error) {
+	return w.writer.Write(p)
+}
+
+func (w *switchWriter) sinks() []io.Writer {
+	return []io.Writer{w.writer}
+}
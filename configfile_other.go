@@ -0,0 +1,8 @@
+//go:build !(aix || android || darwin || dragonfly || freebsd || hurd || illumos || ios || linux || netbsd || openbsd || solaris)
+
+package logger
+
+import "os"
+
+// notifyReload does nothing, there is no SIGHUP on this operating system.
+func notifyReload(signals chan<- os.Signal) {}
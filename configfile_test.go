@@ -0,0 +1,268 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	out, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(out)), "\n")
+}
+
+func TestWatchConfigFile_Reload(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.json")
+	first := filepath.Join(dir, "first.log")
+	second := filepath.Join(dir, "second.log")
+	writeConfigFile(t, path, `{
+		"levels": {"payment": "DEBUG"},
+		"sinks": ["`+filepath.ToSlash(first)+`"],
+		"redaction": {"keys": ["password"]}
+	}`)
+
+	log, watcher, err := WatchConfigFile(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+	payment := log.Named("payment")
+
+	payment.Debug("first", "password", "secret")
+	log.Debug("hidden")
+
+	writeConfigFile(t, path, `{"level": "TRACE", "sinks": ["`+filepath.ToSlash(second)+`"]}`)
+	if err := watcher.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	payment.Debug("second", "password", "secret")
+	log.Trace("visible")
+
+	firstLines := readLines(t, first)
+	if len(firstLines) != 1 || !strings.HasSuffix(firstLines[0], `"message": "first", "logger": "payment", "password": "[REDACTED]"}`) {
+		t.Errorf("Records of first config are incorrect: %q", firstLines)
+	}
+	secondLines := readLines(t, second)
+	if len(secondLines) != 2 || !strings.HasSuffix(secondLines[0], `"message": "second", "logger": "payment", "password": "secret"}`) ||
+		!strings.HasSuffix(secondLines[1], `"level": "TRACE", "message": "visible"}`) {
+		t.Errorf("Records of second config are incorrect: %q", secondLines)
+	}
+}
+
+func TestWatchConfigFile_FatalClosesSinks(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.json")
+	first := filepath.Join(dir, "first.log")
+	second := filepath.Join(dir, "second.log")
+	writeConfigFile(t, path, `{"sinks": ["`+filepath.ToSlash(first)+`", "`+filepath.ToSlash(second)+`"]}`)
+
+	exited := false
+	log, watcher, err := WatchConfigFile(path, 0, WithExitFunc(func(code int) { exited = true }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+	files := watcher.writer.closers
+
+	log.Named("payment").Fatal("Cannot start")
+
+	if !exited {
+		t.Errorf("Exit func was not called")
+	}
+	for _, sink := range []string{first, second} {
+		if lines := readLines(t, sink); len(lines) != 1 || !strings.Contains(lines[0], `"message": "Cannot start"`) {
+			t.Errorf("Records of %s are incorrect: %q", sink, lines)
+		}
+	}
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 file sinks, got %d", len(files))
+	}
+	for _, f := range files {
+		if _, err := f.(*os.File).Write([]byte("x")); err == nil {
+			t.Errorf("Sink %s was not closed", f.(*os.File).Name())
+		}
+	}
+}
+
+func TestWatchConfigFile_InvalidReload(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.json")
+	output := filepath.Join(dir, "app.log")
+	writeConfigFile(t, path, `{"level": "DEBUG", "sinks": ["`+filepath.ToSlash(output)+`"]}`)
+
+	log, watcher, err := WatchConfigFile(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	invalid := map[string]string{
+		`{"level": "LOUD"}`:                          "level: invalid level: LOUD",
+		`{"levels": {"payment": "x"}}`:               "levels.payment: invalid level: x",
+		`{"sinks": ["/missing/dir/app.log"]}`:        "sinks: invalid log output",
+		`{"sampling": {"first": 1, "tick": "soon"}}`: `sampling.tick: invalid duration "soon"`,
+		`{"redaction": {"patterns": ["phone"]}}`:     `redaction.patterns: unknown pattern "phone"`,
+		`{"level": "DEBUG", "colors": true}`:         `json: unknown field "colors"`,
+		`{"level": "DEBUG"`:                          "unexpected EOF",
+	}
+	for content, expected := range invalid {
+		writeConfigFile(t, path, content)
+		err := watcher.Reload()
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Error is incorrect, Expected %s, Actual %v", expected, err)
+		}
+	}
+	log.Debug("still debug")
+
+	lines := readLines(t, output)
+	if len(lines) != len(invalid)+1 {
+		t.Fatalf("Expected %d records, Actual %d: %q", len(invalid)+1, len(lines), lines)
+	}
+	for _, line := range lines[:len(invalid)] {
+		if !strings.Contains(line, `"level": "ERROR", "message": "Invalid log configuration, keeping the previous one"`) ||
+			!strings.Contains(line, `"error": "`) {
+			t.Errorf("Record is incorrect: %s", line)
+		}
+	}
+	if !strings.Contains(lines[len(invalid)], `"message": "still debug"`) {
+		t.Errorf("Record is incorrect: %s", lines[len(invalid)])
+	}
+}
+
+func TestWatchConfigFile_Poll(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.json")
+	writeConfigFile(t, path, `{"sinks": ["`+filepath.ToSlash(filepath.Join(dir, "app.log"))+`"]}`)
+
+	log, watcher, err := WatchConfigFile(path, 5*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	writeConfigFile(t, path, `{"level": "DEBUG", "sinks": ["`+filepath.ToSlash(filepath.Join(dir, "app.log"))+`"]}`)
+	deadline := time.Now().Add(5 * time.Second)
+	for !log.IsDebugEnabled() {
+		if time.Now().After(deadline) {
+			t.Fatal("Changed configuration was not applied")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWatchConfigFile_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if _, _, err := WatchConfigFile(filepath.Join(dir, "missing.json"), 0); err == nil {
+		t.Errorf("Missing file should fail")
+	}
+
+	path := filepath.Join(dir, "log.json")
+	writeConfigFile(t, path, `{"level": "LOUD"}`)
+	if _, _, err := WatchConfigFile(path, 0); err == nil || !strings.Contains(err.Error(), "invalid level: LOUD") {
+		t.Errorf("Error is incorrect: %v", err)
+	}
+}
+
+func TestSampling(t *testing.T) {
+	s := newSampler(Sampling{First: 2, Thereafter: 3, Tick: time.Hour})
+
+	var written []int
+	for i := 1; i <= 10; i++ {
+		if s.sample(LevelInfo, "repeated", nil) {
+			written = append(written, i)
+		}
+	}
+	if got := len(written); got != 4 || written[2] != 5 || written[3] != 8 {
+		t.Errorf("Written records are incorrect: %v", written)
+	}
+	if !s.sample(LevelInfo, "other", nil) || !s.sample(LevelDebug, "repeated", nil) {
+		t.Errorf("Other messages and levels should be counted separately")
+	}
+	if !s.sample(LevelError, "repeated", nil) {
+		t.Errorf("Errors should never be dropped")
+	}
+
+	s.set(Sampling{})
+	for i := 0; i < 10; i++ {
+		if !s.sample(LevelInfo, "repeated", nil) {
+			t.Fatalf("Zero Sampling should write all records")
+		}
+	}
+}
+
+func TestSampling_DefaultTick(t *testing.T) {
+	for _, tick := range []time.Duration{0, -time.Second} {
+		s := newSampler(Sampling{First: 2, Tick: tick})
+		written := 0
+		for i := 0; i < 10; i++ {
+			if s.sample(LevelInfo, "repeated", nil) {
+				written++
+			}
+		}
+		if written != 2 {
+			t.Errorf("Tick %s: Expected 2 written records, Actual %d", tick, written)
+		}
+	}
+
+	s := newSampler(Sampling{Thereafter: 5})
+	written := 0
+	for i := 0; i < 10; i++ {
+		if s.sample(LevelInfo, "repeated", nil) {
+			written++
+		}
+	}
+	if written != 2 {
+		t.Errorf("Thereafter without Tick: Expected 2 written records, Actual %d", written)
+	}
+}
+
+func TestSampling_Clock(t *testing.T) {
+	out := &bytes.Buffer{}
+	clock := NewFakeClock(time.Date(2022, 2, 1, 13, 1, 2, 0, time.UTC))
+	log := NewWithWriter(LvlInfo, out, WithClock(clock), WithSampling(Sampling{First: 1, Tick: time.Minute}))
+
+	log.Info("repeated")
+	log.Info("repeated")
+	clock.Add(time.Minute)
+	log.Info("repeated")
+
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 2 ||
+		!strings.Contains(lines[1], `"ts": "2022-02-01T13:02:02.000000Z"`) {
+		t.Errorf("Sampling should reset after a tick of the logger's clock: %q", lines)
+	}
+}
+
+func TestWatchConfigFile_SamplingWithoutTick(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.json")
+	output := filepath.Join(dir, "app.log")
+	writeConfigFile(t, path, `{"sinks": ["`+filepath.ToSlash(output)+`"], "sampling": {"first": 2}}`)
+
+	log, watcher, err := WatchConfigFile(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	for i := 0; i < 5; i++ {
+		log.Info("repeated")
+	}
+	if lines := readLines(t, output); len(lines) != 2 {
+		t.Errorf("Expected 2 records, Actual %d: %q", len(lines), lines)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build aix || android || darwin || dragonfly || freebsd || hurd || illumos || ios || linux || netbsd || openbsd || solaris
+
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyReload makes SIGHUP reload the configuration file. The operating systems are listed,
+// because the unix build constraint needs Go 1.19.
+func notifyReload(signals chan<- os.Signal) {
+	signal.Notify(signals, syscall.SIGHUP)
+}
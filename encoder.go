@@ -1,9 +1,13 @@
 package logger
 
 import (
+	"encoding"
 	"encoding/json"
 	"fmt"
+	"math"
+	"reflect"
 	"strconv"
+	"time"
 )
 
 // JSONValueWriter can be implemented to write values types that are not directly supported
@@ -16,25 +20,71 @@ type JSONValueWriter interface {
 	WriteJSONValue(sw *StackWriter) (n int, err error)
 }
 
+// encoder holds the per-logger encoding settings.
+type encoder struct {
+	redactor       *Redactor
+	durationFormat DurationFormat
+	bytesFormat    BytesFormat
+}
+
+var defaultEncoder = encoder{}
+
 func encodeKey(sw *StackWriter, key interface{}) (n int, err error) {
 	switch k := key.(type) {
 	case string:
 		return sw.WriteJSONString(noescape_string(&k))
 	case fmt.Stringer:
-		return sw.WriteJSONString(noescape_stringer(&k).String())
+		if isNilPointer(noescape_stringer(&k)) {
+			return sw.WriteJSONString("INVALID_KEY_<nil>")
+		}
+		return sw.WriteJSONString(safeString(noescape_stringer(&k)))
 	default:
 		return sw.WriteJSONString(fmt.Sprintf("INVALID_KEY_%v", noescape_interface(&k)))
 	}
 }
 
+// safeString calls s.String() and returns "<PANIC: ...>" if it panics.
+func safeString(s fmt.Stringer) (str string) {
+	defer func() {
+		if r := recover(); r != nil {
+			str = fmt.Sprintf("<PANIC: %v>", r)
+		}
+	}()
+	return s.String()
+}
+
 func encodeValue(sw *StackWriter, value interface{}) (n int, err error) {
+	return defaultEncoder.encodeValue(sw, value)
+}
+
+// encodeField encodes the value of a key/value pair. Values of sensitive keys are redacted.
+func (e *encoder) encodeField(sw *StackWriter, key interface{}, value interface{}) (n int, err error) {
+	if e.redactor != nil {
+		if k, ok := key.(string); ok && e.redactor.matchesKey(noescape_string(&k)) {
+			return e.redactor.writeRedacted(sw, noescape_interface(&value))
+		}
+	}
+	return e.encodeValue(sw, value)
+}
+
+// encodeValue writes a JSON value. Primitive types, time.Time, time.Duration, []byte, Field
+// and RawJSON are written directly. Other types are checked in this order:
+//
+//  0. LazyValue, the result of LogValue is encoded
+//  1. JSONValueWriter
+//  2. json.Marshaler, the output is validated and compacted
+//  3. encoding.TextMarshaler, the text is written as string
+//  4. error, the result of Error is written as string
+//  5. fmt.Stringer, the result of String is written as string
+//  6. reflection: structs with log tags are encoded field by field, everything else by json.Marshal
+func (e *encoder) encodeValue(sw *StackWriter, value interface{}) (n int, err error) {
 	switch v := value.(type) {
 	case string:
-		return sw.WriteJSONString(noescape_string(&v))
+		return e.encodeString(sw, noescape_string(&v))
 	case float32:
-		return sw.Write(string(strconv.AppendFloat(nil, float64(v), 'f', 6, 32)))
+		return writeFloat(sw, float64(v), 32)
 	case float64:
-		return sw.Write(string(strconv.AppendFloat(nil, v, 'f', 6, 64)))
+		return writeFloat(sw, v, 64)
 	case int:
 		return sw.Write(strconv.Itoa(v))
 	case int32:
@@ -47,15 +97,156 @@ func encodeValue(sw *StackWriter, value interface{}) (n int, err error) {
 		return sw.Write(strconv.FormatUint(v, 10))
 	case bool:
 		return sw.Write(strconv.FormatBool(v))
-	case fmt.Stringer:
-		return sw.WriteJSONString(noescape_stringer(&v).String())
+	case time.Time:
+		return e.encodeTime(sw, v)
+	case time.Duration:
+		return e.encodeDuration(sw, v)
+	case []byte:
+		return e.encodeBytes(sw, v)
+	case Field:
+		return e.encodeTypedValue(sw, &v)
+	case RawJSON:
+		return e.encodeRawJSON(sw, v)
+	default:
+		return e.encodeUserValue(sw, noescape_interface(&v))
+	}
+}
+
+// encodeUserValue encodes values by calling methods of user types. Nil pointers are written
+// as null without calling any method. If a method panics or returns an error, everything
+// that was written for the value is discarded and "<PANIC: ...>" or "<ERROR: ...>" is
+// written instead, so the record stays valid.
+func (e *encoder) encodeUserValue(sw *StackWriter, value interface{}) (n int, err error) {
+	if isNilPointer(noescape_interface(&value)) {
+		return sw.Write("null")
+	}
+
+	mark := sw.mark()
+	defer func() {
+		if r := recover(); r != nil {
+			sw.rollback(mark)
+			n, err = sw.WriteJSONString(fmt.Sprintf("<PANIC: %v>", r))
+			return
+		}
+		if err != nil {
+			sw.rollback(mark)
+			n, err = sw.WriteJSONString("<ERROR: " + safeError(err) + ">")
+			return
+		}
+		if commitErr := sw.commit(mark); commitErr != nil && err == nil {
+			err = commitErr
+		}
+	}()
+
+	switch v := value.(type) {
+	case LazyValue:
+		return e.encodeValue(sw, resolveLazy(v))
 	case JSONValueWriter:
 		return v.WriteJSONValue(noescape_stackwriterptr(sw))
+	case json.Marshaler:
+		raw, err := v.MarshalJSON()
+		if err != nil {
+			return 0, err
+		}
+		return e.encodeRawJSON(sw, raw)
+	case encoding.TextMarshaler:
+		text, err := v.MarshalText()
+		if err != nil {
+			return 0, err
+		}
+		return e.encodeString(sw, bytesToString(text))
+	case error:
+		return e.encodeString(sw, v.Error())
+	case fmt.Stringer:
+		return e.encodeString(sw, noescape_stringer(&v).String())
 	default:
+		if n, ok, err := e.encodeReflect(sw, noescape_interface(&v)); ok {
+			return n, err
+		}
 		jsonString, err := json.Marshal(noescape_interface(&v))
 		if err != nil {
 			return 0, err
 		}
+		if e.redactor != nil {
+			return e.redactor.writeJSON(sw, jsonString)
+		}
 		return sw.Write(string(jsonString))
 	}
 }
+
+// writeFloat writes f with 6 decimals. NaN and infinities are not valid JSON numbers, they
+// are written as the strings "NaN", "+Inf" and "-Inf".
+func writeFloat(sw *StackWriter, f float64, bitSize int) (n int, err error) {
+	switch {
+	case math.IsNaN(f):
+		return sw.Write(`"NaN"`)
+	case math.IsInf(f, 1):
+		return sw.Write(`"+Inf"`)
+	case math.IsInf(f, -1):
+		return sw.Write(`"-Inf"`)
+	}
+	var buf [32]byte
+	return sw.Write(string(strconv.AppendFloat(buf[:0], f, 'f', 6, bitSize)))
+}
+
+// isNilPointer returns true for nil pointers stored in an interface.
+func isNilPointer(value interface{}) bool {
+	v := reflect.ValueOf(value)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// RawJSON is embedded into the record as is. It is validated and compacted first,
+// invalid JSON is written as string, so the record stays valid.
+type RawJSON []byte
+
+func (e *encoder) encodeRawJSON(sw *StackWriter, raw []byte) (n int, err error) {
+	if !json.Valid(raw) {
+		return sw.WriteJSONString(bytesToString(raw))
+	}
+	if e.redactor != nil {
+		return e.redactor.writeJSON(sw, raw)
+	}
+	return writeCompactJSON(sw, raw)
+}
+
+// writeCompactJSON writes valid JSON without insignificant whitespace, so the record stays
+// on a single line.
+func writeCompactJSON(sw *StackWriter, raw []byte) (n int, err error) {
+	var copyFrom int
+	inString := false
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case ' ', '\t', '\n', '\r':
+			if copyFrom < i {
+				nw, err := sw.Write(bytesToString(raw[copyFrom:i]))
+				n += nw
+				if err != nil {
+					return n, err
+				}
+			}
+			copyFrom = i + 1
+		}
+	}
+
+	if copyFrom < len(raw) {
+		nw, err := sw.Write(bytesToString(raw[copyFrom:]))
+		n += nw
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
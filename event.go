@@ -0,0 +1,188 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// Event is a record that is built by chained calls and written by Msg or Send:
+//
+//	log.InfoEvent().Str("path", path).Int("status", 200).Msg("Request done")
+//
+// Events are pooled, an Event must not be used after Msg or Send was called.
+// Events of disabled levels are nil, all methods of a nil Event are no-ops.
+type Event struct {
+	logger *instance
+	level  Level
+	fields []Field
+}
+
+var eventPool = sync.Pool{
+	New: func() interface{} {
+		return &Event{fields: make([]Field, 0, 16)}
+	},
+}
+
+func (l *instance) newEvent(level Level) *Event {
+	e := eventPool.Get().(*Event)
+	e.logger = l
+	e.level = level
+	return e
+}
+
+func (l *instance) ErrorEvent() *Event {
+	return l.newEvent(LevelError)
+}
+
+func (l *instance) WarnEvent() *Event {
+	return l.newEvent(LevelWarn)
+}
+
+func (l *instance) InfoEvent() *Event {
+	return l.newEvent(LevelInfo)
+}
+
+func (l *instance) DebugEvent() *Event {
+	if !l.enabled(LevelDebug) {
+		return nil
+	}
+	return l.newEvent(LevelDebug)
+}
+
+func (l *instance) TraceEvent() *Event {
+	if !l.enabled(LevelTrace) {
+		return nil
+	}
+	return l.newEvent(LevelTrace)
+}
+
+// Enabled returns false if the event will not be written.
+func (e *Event) Enabled() bool {
+	return e != nil
+}
+
+// Str adds a string field.
+func (e *Event) Str(key string, value string) *Event {
+	if e == nil {
+		return nil
+	}
+	e.fields = append(e.fields, String(key, value))
+	return e
+}
+
+// Int adds an integer field.
+func (e *Event) Int(key string, value int) *Event {
+	if e == nil {
+		return nil
+	}
+	e.fields = append(e.fields, Int(key, value))
+	return e
+}
+
+// Int64 adds an integer field.
+func (e *Event) Int64(key string, value int64) *Event {
+	if e == nil {
+		return nil
+	}
+	e.fields = append(e.fields, Int64(key, value))
+	return e
+}
+
+// Uint64 adds an unsigned integer field.
+func (e *Event) Uint64(key string, value uint64) *Event {
+	if e == nil {
+		return nil
+	}
+	e.fields = append(e.fields, Uint64(key, value))
+	return e
+}
+
+// Float64 adds a floating point field.
+func (e *Event) Float64(key string, value float64) *Event {
+	if e == nil {
+		return nil
+	}
+	e.fields = append(e.fields, Float64(key, value))
+	return e
+}
+
+// Bool adds a boolean field.
+func (e *Event) Bool(key string, value bool) *Event {
+	if e == nil {
+		return nil
+	}
+	e.fields = append(e.fields, Bool(key, value))
+	return e
+}
+
+// Dur adds a time.Duration field.
+func (e *Event) Dur(key string, value time.Duration) *Event {
+	if e == nil {
+		return nil
+	}
+	e.fields = append(e.fields, Duration(key, value))
+	return e
+}
+
+// Time adds a time.Time field.
+func (e *Event) Time(key string, value time.Time) *Event {
+	if e == nil {
+		return nil
+	}
+	e.fields = append(e.fields, Time(key, value))
+	return e
+}
+
+// Err adds an "error" field.
+func (e *Event) Err(err error) *Event {
+	if e == nil {
+		return nil
+	}
+	e.fields = append(e.fields, Err(err))
+	return e
+}
+
+// Interface adds a field for any value.
+func (e *Event) Interface(key string, value interface{}) *Event {
+	if e == nil {
+		return nil
+	}
+	e.fields = append(e.fields, Object(key, value))
+	return e
+}
+
+// Fields adds typed fields.
+func (e *Event) Fields(fields ...Field) *Event {
+	if e == nil {
+		return nil
+	}
+	e.fields = append(e.fields, fields...)
+	return e
+}
+
+// Msg writes the event with the given message and returns it to the pool.
+func (e *Event) Msg(msg string) {
+	if e == nil {
+		return
+	}
+	e.logger.log(e.level, msg, nil, e.fields, nil)
+	e.release()
+}
+
+// Send writes the event with an empty message and returns it to the pool.
+func (e *Event) Send() {
+	if e == nil {
+		return
+	}
+	e.logger.log(e.level, "", nil, e.fields, nil)
+	e.release()
+}
+
+func (e *Event) release() {
+	for i := range e.fields {
+		e.fields[i] = Field{}
+	}
+	e.fields = e.fields[:0]
+	e.logger = nil
+	eventPool.Put(e)
+}
@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger_InfoEvent(t *testing.T) {
+	out := bytes.NewBufferString("")
+	logger := NewWithWriter(LvlInfo, out)
+	logger.InfoEvent().
+		Str("string", "value").
+		Int("int", 3).
+		Float64("float64", 1.25).
+		Bool("bool", false).
+		Dur("duration", time.Second).
+		Err(errors.New("failed")).
+		Interface("object", []int{1, 2}).
+		Msg("done")
+
+	want := `"message": "done", "string": "value", "int": 3, "float64": 1.250000, "bool": false, ` +
+		`"duration": "1s", "error": "failed", "object": [1,2]}` + "\n"
+	if got := out.String(); !strings.HasSuffix(got, want) {
+		t.Errorf("Written string does not match.\nWant suffix: %s\nGot: %s", want, got)
+	}
+}
+
+func TestLogger_WarnEvent_CallerInfo(t *testing.T) {
+	out := bytes.NewBufferString("")
+	logger := NewWithWriter(LvlInfo, out)
+	logger.WarnEvent().Send()
+
+	if !strings.Contains(out.String(), "event_test.go") {
+		t.Errorf("Caller info does not point to the test file: %s", out.String())
+	}
+}
+
+func TestLogger_DebugEvent_Disabled(t *testing.T) {
+	out := bytes.NewBufferString("")
+	logger := NewWithWriter(LvlInfo, out)
+
+	e := logger.DebugEvent()
+	if e.Enabled() {
+		t.Errorf("Debug event is enabled")
+	}
+	e.Str("k", "v").Int("n", 1).Err(nil).Msg("not written")
+	logger.TraceEvent().Str("k", "v").Send()
+
+	if out.Len() > 0 {
+		t.Errorf("Disabled levels were written: %s", out.String())
+	}
+}
+
+func TestLogger_InfoEvent_ZeroAlloc(t *testing.T) {
+	logger := NewWithWriter(LvlInfo, io.Discard)
+	longstring := makeString(4096)
+	err := errors.New("failed")
+	allocs := testing.AllocsPerRun(10, func() {
+		logger.InfoEvent().Str("string", longstring).Int("int", 34875634).Err(err).Msg("Lorem ipsum")
+		logger.DebugEvent().Str("string", longstring).Int("int", 34875634).Msg("Lorem ipsum")
+	})
+
+	if allocs > 0.0 {
+		t.Errorf("Allocs detected! Want 0 allocs, got %f", allocs)
+	}
+}
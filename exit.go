@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+var (
+	exitHooksMutex sync.Mutex
+	exitHooks      []func()
+)
+
+// RegisterExitHook registers fn to be called by Fatal before the process exits. Hooks are
+// called in the order they were registered, after the writer of the logger was closed.
+// Use them to flush other buffers, e.g. of tracing or metrics clients.
+func RegisterExitHook(fn func()) {
+	exitHooksMutex.Lock()
+	defer exitHooksMutex.Unlock()
+	exitHooks = append(exitHooks, fn)
+}
+
+// WithExitFunc replaces os.Exit, which is called by Fatal. Meant for tests.
+func WithExitFunc(fn func(code int)) Option {
+	return func(l *instance) {
+		l.exitFunc = fn
+	}
+}
+
+// exit flushes and closes the writer, runs the exit hooks and exits with status 1.
+func (l *instance) exit() {
+	l.closeWriter()
+	runExitHooks()
+	l.exitFunc(1)
+}
+
+// closeWriter flushes and closes the sinks of the writer, see closeSink. Errors are ignored,
+// there is nowhere left to report them.
+func (l *instance) closeWriter() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	closeSink(l.writer)
+}
+
+// forwardingWriter is implemented by the writers of this package that forward the records to
+// other writers, e.g. MultiWriter and the writer of WatchConfigFile.
+type forwardingWriter interface {
+	sinks() []io.Writer
+}
+
+// closeSink flushes writer and closes it, unless it is stdout or stderr. Writers that forward
+// to other writers are not closed themselves, the writers behind them are.
+func closeSink(writer io.Writer) {
+	if fw, ok := writer.(forwardingWriter); ok {
+		for _, sink := range fw.sinks() {
+			closeSink(sink)
+		}
+		return
+	}
+	if f, ok := writer.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	if s, ok := writer.(interface{ Sync() error }); ok {
+		_ = s.Sync()
+	}
+	if writer == os.Stdout || writer == os.Stderr {
+		return
+	}
+	if c, ok := writer.(io.Closer); ok {
+		_ = c.Close()
+	}
+}
+
+// runExitHooks calls all exit hooks. A panicking hook does not stop the others.
+func runExitHooks() {
+	exitHooksMutex.Lock()
+	hooks := make([]func(), len(exitHooks))
+	copy(hooks, exitHooks)
+	exitHooksMutex.Unlock()
+
+	for _, fn := range hooks {
+		func() {
+			defer func() {
+				_ = recover()
+			}()
+			fn()
+		}()
+	}
+}
@@ -0,0 +1,148 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type testSink struct {
+	bytes.Buffer
+	calls []string
+}
+
+func (s *testSink) Flush() error {
+	s.calls = append(s.calls, "flush")
+	return nil
+}
+
+func (s *testSink) Close() error {
+	s.calls = append(s.calls, "close")
+	return nil
+}
+
+func TestLogger_Fatal(t *testing.T) {
+	sink := &testSink{}
+	exitCode := -1
+	logger := NewWithWriter(LvlInfo, sink, WithExitFunc(func(code int) {
+		sink.calls = append(sink.calls, "exit")
+		exitCode = code
+	}))
+
+	RegisterExitHook(func() {
+		sink.calls = append(sink.calls, "hook")
+	})
+	RegisterExitHook(func() {
+		panic("broken hook")
+	})
+	defer func() {
+		exitHooks = nil
+	}()
+
+	logger.Fatal("Cannot start", "port", 8080)
+
+	if exitCode != 1 {
+		t.Errorf("Exit code is incorrect, Expected 1, Actual %d", exitCode)
+	}
+	if got := strings.Join(sink.calls, ","); got != "flush,close,hook,exit" {
+		t.Errorf("Shutdown order is incorrect, Expected flush,close,hook,exit, Actual %s", got)
+	}
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(sink.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record["level"] != LvlFatal || record["message"] != "Cannot start" || record["port"] != 8080.0 {
+		t.Errorf("Record is incorrect: %s", sink.String())
+	}
+	if !strings.Contains(record["caller_func"].(string), "TestLogger_Fatal") {
+		t.Errorf("caller_func is incorrect: %v", record["caller_func"])
+	}
+	if !strings.HasPrefix(record["stacktrace"].(string), "github.com/fond-of-vertigo/logger.TestLogger_Fatal\n\t") {
+		t.Errorf("stacktrace is incorrect: %v", record["stacktrace"])
+	}
+}
+
+func TestLogger_Fatal_ClosesAllSinks(t *testing.T) {
+	first, second := &testSink{}, &testSink{}
+	exited := false
+	logger := NewWithWriter(LvlInfo, MultiWriter(first, second), WithFormat(FormatLogfmt),
+		WithExitFunc(func(code int) { exited = true }))
+
+	logger.Named("child").Fatal("Cannot start")
+
+	if !exited {
+		t.Errorf("Exit func was not called")
+	}
+	for name, sink := range map[string]*testSink{"first": first, "second": second} {
+		if got := strings.Join(sink.calls, ","); got != "flush,close" {
+			t.Errorf("Calls of %s sink are incorrect, Expected flush,close, Actual %s", name, got)
+		}
+		if !strings.Contains(sink.String(), `level=FATAL message="Cannot start" logger=child`) {
+			t.Errorf("Record of %s sink is incorrect: %s", name, sink.String())
+		}
+	}
+}
+
+func TestLogger_Fatalf(t *testing.T) {
+	out := &bytes.Buffer{}
+	exited := false
+	logger := NewWithWriter(LvlInfo, out, WithExitFunc(func(code int) { exited = true }))
+
+	logger.Fatalf("Cannot listen on port %d", 8080)
+
+	if !exited {
+		t.Errorf("Exit func was not called")
+	}
+	if !strings.Contains(out.String(), `"message": "Cannot listen on port 8080"`) {
+		t.Errorf("Record is incorrect: %s", out.String())
+	}
+}
+
+func TestLogger_Panic(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := NewWithWriter(LvlInfo, out)
+
+	defer func() {
+		r := recover()
+		if r != "Invalid state" {
+			t.Errorf("Panic value is incorrect, Expected Invalid state, Actual %v", r)
+		}
+
+		var record map[string]interface{}
+		if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+			t.Fatal(err)
+		}
+		if record["level"] != LvlPanic || record["state"] != "closed" {
+			t.Errorf("Record is incorrect: %s", out.String())
+		}
+		if _, ok := record["stacktrace"]; !ok {
+			t.Errorf("stacktrace is missing: %s", out.String())
+		}
+	}()
+
+	logger.Panic("Invalid state", "state", "closed")
+	t.Errorf("Panic did not panic")
+}
+
+func TestLogger_Panicf(t *testing.T) {
+	logger := NewWithWriter(LvlInfo, &bytes.Buffer{})
+
+	defer func() {
+		if r := recover(); r != "Invalid state closed" {
+			t.Errorf("Panic value is incorrect, Expected Invalid state closed, Actual %v", r)
+		}
+	}()
+
+	logger.Panicf("Invalid state %s", "closed")
+}
+
+func TestGetValidLevel_FatalPanic(t *testing.T) {
+	for input, expected := range map[string]string{"fatal": LvlFatal, "Panic": LvlPanic} {
+		level, err := GetValidLevel(input)
+		if err != nil || level != expected {
+			t.Errorf("GetValidLevel(%q) = %q, %v, Expected %q", input, level, err, expected)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// FieldType selects how a Field is encoded.
+type FieldType uint8
+
+const (
+	UnknownType FieldType = iota
+	StringType
+	Int64Type
+	Uint64Type
+	Float64Type
+	BoolType
+	DurationType
+	TimeType
+	ErrorType
+	ObjectType
+)
+
+// Field is a typed key/value pair. Fields are encoded directly into the StackWriter
+// without boxing numbers into interfaces, use them with ErrorF, WarnF, InfoF, DebugF and TraceF.
+type Field struct {
+	Key     string
+	Type    FieldType
+	nanos   int32 // nanoseconds of TimeType, integer holds the Unix seconds
+	integer int64
+	str     string
+	iface   interface{}
+}
+
+// String creates a string field.
+func String(key string, value string) Field {
+	return Field{Key: key, Type: StringType, str: value}
+}
+
+// Int creates an integer field.
+func Int(key string, value int) Field {
+	return Field{Key: key, Type: Int64Type, integer: int64(value)}
+}
+
+// Int64 creates an integer field.
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Type: Int64Type, integer: value}
+}
+
+// Uint64 creates an unsigned integer field.
+func Uint64(key string, value uint64) Field {
+	return Field{Key: key, Type: Uint64Type, integer: int64(value)}
+}
+
+// Float64 creates a floating point field. NaN and infinities are written as the strings
+// "NaN", "+Inf" and "-Inf".
+func Float64(key string, value float64) Field {
+	return Field{Key: key, Type: Float64Type, integer: int64(math.Float64bits(value))}
+}
+
+// Bool creates a boolean field.
+func Bool(key string, value bool) Field {
+	var i int64
+	if value {
+		i = 1
+	}
+	return Field{Key: key, Type: BoolType, integer: i}
+}
+
+// Duration creates a time.Duration field.
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Type: DurationType, integer: int64(value)}
+}
+
+// Time creates a time.Time field. Like values of type time.Time, it is written as RFC3339
+// string in UTC with microseconds, independent of WithTimeFormat. Years before 0 and after
+// 9999 are written in full, like time.RFC3339Nano.
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, Type: TimeType, integer: value.Unix(), nanos: int32(value.Nanosecond())}
+}
+
+// Err creates an "error" field with the error message, or null if err is nil.
+func Err(err error) Field {
+	return Field{Key: "error", Type: ErrorType, iface: err}
+}
+
+// Object creates a field for any value, it is encoded like the values of the key/value API.
+func Object(key string, value interface{}) Field {
+	return Field{Key: key, Type: ObjectType, iface: value}
+}
+
+// Value returns the value of the field as interface.
+func (f Field) Value() interface{} {
+	switch f.Type {
+	case StringType:
+		return f.str
+	case Int64Type:
+		return f.integer
+	case Uint64Type:
+		return uint64(f.integer)
+	case Float64Type:
+		return math.Float64frombits(uint64(f.integer))
+	case BoolType:
+		return f.integer == 1
+	case DurationType:
+		return time.Duration(f.integer)
+	case TimeType:
+		return time.Unix(f.integer, int64(f.nanos)).UTC()
+	default:
+		return f.iface
+	}
+}
+
+// WriteJSONValue writes the value of the field, so fields can also be passed as values.
+func (f Field) WriteJSONValue(sw *StackWriter) (n int, err error) {
+	return defaultEncoder.encodeTypedValue(sw, &f)
+}
+
+func (e *encoder) encodeTypedField(sw *StackWriter, f *Field) (n int, err error) {
+	if e.redactor != nil && e.redactor.matchesKey(f.Key) {
+		return e.redactor.writeRedacted(sw, f.Value())
+	}
+	return e.encodeTypedValue(sw, f)
+}
+
+func (e *encoder) encodeTypedValue(sw *StackWriter, f *Field) (n int, err error) {
+	switch f.Type {
+	case StringType:
+		return e.encodeString(sw, f.str)
+	case Int64Type:
+		var buf [20]byte
+		return sw.Write(string(strconv.AppendInt(buf[:0], f.integer, 10)))
+	case Uint64Type:
+		var buf [20]byte
+		return sw.Write(string(strconv.AppendUint(buf[:0], uint64(f.integer), 10)))
+	case Float64Type:
+		return writeFloat(sw, math.Float64frombits(uint64(f.integer)), 64)
+	case BoolType:
+		return sw.Write(strconv.FormatBool(f.integer == 1))
+	case DurationType:
+		return e.encodeDuration(sw, time.Duration(f.integer))
+	case TimeType:
+		return e.encodeTime(sw, time.Unix(f.integer, int64(f.nanos)))
+	case ErrorType:
+		if f.iface == nil {
+			return sw.Write("null")
+		}
+		if isNilPointer(f.iface) {
+			return sw.Write("null")
+		}
+		return e.encodeString(sw, safeError(f.iface.(error)))
+	default:
+		return e.encodeValue(sw, f.iface)
+	}
+}
+
+func (e *encoder) encodeString(sw *StackWriter, s string) (n int, err error) {
+	if e.redactor != nil {
+		return sw.WriteJSONString(e.redactor.redactString(s))
+	}
+	return sw.WriteJSONString(s)
+}
+
+// safeError calls err.Error() and returns "<PANIC: ...>" if it panics.
+func safeError(err error) (str string) {
+	defer func() {
+		if r := recover(); r != nil {
+			str = fmt.Sprintf("<PANIC: %v>", r)
+		}
+	}()
+	return err.Error()
+}
@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestLogger_InfoF(t *testing.T) {
+	out := bytes.NewBufferString("")
+	logger := NewWithWriter(LvlInfo, out)
+	ts := time.Date(2022, 2, 1, 13, 1, 2, 123456000, time.UTC)
+	logger.InfoF("Test msg",
+		String("string", "value"),
+		Int("int", -1),
+		Int64("int64", 9223372036854775807),
+		Uint64("uint64", 18446744073709551615),
+		Float64("float64", 1.25),
+		Bool("bool", true),
+		Duration("duration", 1500*time.Millisecond),
+		Time("time", ts),
+		Err(errors.New("failed")),
+		Object("object", map[string]int{"a": 1}))
+
+	want := `"message": "Test msg", "string": "value", "int": -1, "int64": 9223372036854775807, ` +
+		`"uint64": 18446744073709551615, "float64": 1.250000, "bool": true, "duration": "1.5s", ` +
+		`"time": "2022-02-01T13:01:02.123456Z", "error": "failed", "object": {"a":1}}` + "\n"
+	if got := out.String(); !bytes.HasSuffix([]byte(got), []byte(want)) {
+		t.Errorf("Written string does not match.\nWant suffix: %s\nGot: %s", want, got)
+	}
+	if !json.Valid(out.Bytes()) {
+		t.Errorf("Output is not valid JSON: %s", out.String())
+	}
+}
+
+func TestTime_OutsideUnixNanoRange(t *testing.T) {
+	for _, ts := range []time.Time{
+		{},
+		time.Date(1500, 1, 2, 3, 4, 5, 6000, time.UTC),
+		time.Date(9999, 12, 31, 23, 59, 59, 999999000, time.FixedZone("CET", 3600)),
+		time.Date(-5, 3, 4, 5, 6, 7, 8, time.UTC),
+		time.Unix(1e18, 0),
+	} {
+		out := &bytes.Buffer{}
+		logger := NewWithWriter(LvlInfo, out, WithTimeFormat(TimeNone))
+		logger.InfoF("msg", Time("t", ts))
+		logger.Info("msg", "t", ts)
+		logger.InfoEvent().Time("t", ts).Msg("msg")
+
+		want := `{"level": "INFO", "message": "msg", "t": ` + string(AppendLogTime(nil, ts, TimeRFC3339Micro, nil)) + `}` + "\n"
+		if out.String() != want+want+want {
+			t.Errorf("Time %s is incorrect, Expected\n%s\nActual\n%s", ts, want+want+want, out.String())
+		}
+		if v := Time("t", ts).Value().(time.Time); !v.Equal(ts) {
+			t.Errorf("Value is incorrect, Expected %s, Actual %s", ts, v)
+		}
+	}
+}
+
+func TestFloat64_NonFinite(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := NewWithWriter(LvlInfo, out, WithTimeFormat(TimeNone))
+	logger.InfoF("msg", Float64("nan", math.NaN()), Float64("inf", math.Inf(1)), Float64("-inf", math.Inf(-1)))
+	logger.Info("msg", "nan", math.NaN(), "inf", float32(math.Inf(1)), "struct", testFloats{Value: math.Inf(-1)})
+
+	want := `{"level": "INFO", "message": "msg", "nan": "NaN", "inf": "+Inf", "-inf": "-Inf"}
+{"level": "INFO", "message": "msg", "nan": "NaN", "inf": "+Inf", "struct": {"value":"-Inf"}}
+`
+	if out.String() != want {
+		t.Errorf("Output is incorrect, Expected\n%s\nActual\n%s", want, out.String())
+	}
+}
+
+type testFloats struct {
+	Value float64 `log:"name=value"`
+}
+
+func TestLogger_DebugF_Disabled(t *testing.T) {
+	out := bytes.NewBufferString("")
+	logger := NewWithWriter(LvlInfo, out)
+	logger.DebugF("Test msg", String("k", "v"))
+	logger.TraceF("Test msg", String("k", "v"))
+	if out.Len() > 0 {
+		t.Errorf("Disabled levels were written: %s", out.String())
+	}
+}
+
+func TestLogger_InfoF_Hooks(t *testing.T) {
+	out := bytes.NewBufferString("")
+	logger := NewWithWriter(LvlInfo, out, WithHooks(func(e *Entry) bool {
+		if v, ok := e.Get("n"); !ok || v.(Field).Value() != int64(3) {
+			t.Errorf("Field n not passed to hook: %v", e.KeysAndValues)
+		}
+		e.Set("n", 4)
+		return true
+	}))
+	logger.InfoF("Test msg", Int("n", 3), Err(nil))
+
+	want := `"n": 4, "error": null}` + "\n"
+	if got := out.String(); !bytes.HasSuffix([]byte(got), []byte(want)) {
+		t.Errorf("Written string does not match.\nWant suffix: %s\nGot: %s", want, got)
+	}
+}
+
+func TestLogger_InfoF_ZeroAlloc(t *testing.T) {
+	logger := NewWithWriter(LvlInfo, io.Discard)
+	longstring := makeString(4096)
+	now := time.Now()
+	err := errors.New("failed")
+	allocs := testing.AllocsPerRun(1, func() {
+		logger.InfoF("Lorem ipsum",
+			String("string", longstring),
+			Int64("int64", 34875634),
+			Float64("float64", 1.25),
+			Bool("bool", true),
+			Time("time", now),
+			Duration("duration", time.Second),
+			Err(err))
+	})
+
+	if allocs > 0.0 {
+		t.Errorf("Allocs detected! Want 0 allocs, got %f", allocs)
+	}
+}
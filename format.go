@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+)
+
+// escapingWriter JSON-escapes everything that fmt writes into the StackWriter.
+type escapingWriter struct {
+	sw *StackWriter
+}
+
+func (ew escapingWriter) Write(p []byte) (n int, err error) {
+	_, err = ew.sw.WriteEscaped(bytesToString(p))
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// writeFormatted writes the formatted message as JSON string without building an
+// intermediate string.
+func writeFormatted(sw *StackWriter, format string, args []interface{}) (n int, err error) {
+	n, err = sw.Write("\"")
+	if err != nil {
+		return n, err
+	}
+
+	var w io.Writer = escapingWriter{sw: sw}
+	nw, err := fmt.Fprintf(noescape_writer(&w), format, args...)
+	n += nw
+	if err != nil {
+		return n, err
+	}
+
+	nw, err = sw.Write("\"")
+	n += nw
+	return n, err
+}
+
+// nonNilArgs makes sure a call without args is still formatted, e.g. to unescape %%.
+func nonNilArgs(args []interface{}) []interface{} {
+	if args == nil {
+		return noArgs
+	}
+	return args
+}
+
+var noArgs = []interface{}{}
@@ -0,0 +1,110 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLogger_Infof(t *testing.T) {
+	long := makeString(3 * bufSize)
+	tests := []struct {
+		name        string
+		format      string
+		args        []interface{}
+		wantMessage string
+	}{{
+		name:        "Format verbs",
+		format:      "Test %d: %s %v",
+		args:        []interface{}{1, "Lorem ipsum", true},
+		wantMessage: "Test 1: Lorem ipsum true",
+	}, {
+		name:        "Escape formatted content",
+		format:      "quote %q, newline %s",
+		args:        []interface{}{"a", "x\ny"},
+		wantMessage: "quote \"a\", newline x\ny",
+	}, {
+		name:        "Without args",
+		format:      "100%% done",
+		wantMessage: "100% done",
+	}, {
+		name:        "Longer than the buffer",
+		format:      "long %s",
+		args:        []interface{}{long},
+		wantMessage: "long " + long,
+	},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := bytes.NewBufferString("")
+			logger := NewWithWriter(LvlInfo, out)
+			logger.Infof(tt.format, tt.args...)
+
+			actual := map[string]interface{}{}
+			if err := json.Unmarshal(out.Bytes(), &actual); err != nil {
+				t.Fatalf("Invalid JSON: %s\n%s", err, out.String())
+			}
+			if actual["message"] != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", actual["message"], tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestLogger_Debugf_Lazy(t *testing.T) {
+	calls := 0
+	arg := &testCountingStringer{calls: &calls}
+	out := bytes.NewBufferString("")
+	logger := NewWithWriter(LvlInfo, out)
+	logger.Debugf("value %s", arg)
+	logger.Tracef("value %s", arg)
+
+	if calls != 0 || out.Len() > 0 {
+		t.Errorf("Disabled level was formatted %d times: %s", calls, out.String())
+	}
+
+	logger.Warnf("value %s", arg)
+	if calls != 1 || !strings.Contains(out.String(), `"message": "value counted"`) {
+		t.Errorf("Enabled level was formatted %d times: %s", calls, out.String())
+	}
+	if !strings.Contains(out.String(), "format_test.go") {
+		t.Errorf("Caller info does not point to the test file: %s", out.String())
+	}
+}
+
+func TestLogger_Infof_Hooks(t *testing.T) {
+	out := bytes.NewBufferString("")
+	logger := NewWithWriter(LvlInfo, out, WithHooks(func(e *Entry) bool {
+		return e.Message != "drop 1"
+	}))
+	logger.Infof("drop %d", 1)
+	logger.Infof("keep %d", 2)
+
+	if strings.Contains(out.String(), "drop") || !strings.Contains(out.String(), `"message": "keep 2"`) {
+		t.Errorf("Hook did not get the formatted message: %s", out.String())
+	}
+}
+
+func TestLogger_Infof_ZeroAlloc(t *testing.T) {
+	logger := NewWithWriter(LvlInfo, io.Discard)
+	longstring := makeString(4096)
+	allocs := testing.AllocsPerRun(10, func() {
+		logger.Infof("Lorem %s ipsum %d", longstring, 1)
+		logger.Debugf("Lorem %s ipsum %d", longstring, 1)
+	})
+
+	if allocs > 0.0 {
+		t.Errorf("Allocs detected! Want 0 allocs, got %f", allocs)
+	}
+}
+
+type testCountingStringer struct {
+	calls *int
+}
+
+func (tcs *testCountingStringer) String() string {
+	*tcs.calls++
+	return "counted"
+}
@@ -0,0 +1,266 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+var stackTraceValue = regexp.MustCompile(`("(?:stacktrace|error\.stack_trace|stack_trace|error\.stack)": )"(?:[^"\\]|\\.)*"`)
+
+// callerFileLine and callerLine match the line numbers of callers, which change whenever
+// the test files are edited.
+var (
+	callerFileLine = regexp.MustCompile(`(\.go:)\d+"`)
+	callerLine     = regexp.MustCompile(`("(?:line|log\.origin\.file\.line)": )"?\d+"?`)
+)
+
+// goldenTime is the time of the fake clock of golden records.
+var goldenTime = time.Date(2022, 2, 1, 13, 1, 2, 123456789, time.UTC)
+
+// normalizeRecords replaces the values that change between machines or with edits of the
+// tests: stack traces, the directory of the source files and the line numbers of callers.
+func normalizeRecords(out string) string {
+	_, file, _, _ := runtime.Caller(0)
+	out = strings.ReplaceAll(out, filepath.Dir(file)+"/", "")
+	out = stackTraceValue.ReplaceAllString(out, `$1"<stacktrace>"`)
+	out = callerFileLine.ReplaceAllString(out, `$1<line>"`)
+	return callerLine.ReplaceAllString(out, `$1"<line>"`)
+}
+
+// assertGolden compares actual with testdata/<name>.golden, or updates the file with -update.
+func assertGolden(t *testing.T, name string, actual string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(actual), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v, run go test -update to create it", err)
+	}
+	if actual != string(expected) {
+		t.Errorf("Output does not match %s, run go test -update after checking the changes.\nExpected\n%s\nActual\n%s", path, expected, actual)
+	}
+}
+
+// assertValidRecords fails the test if a line of out is not a valid JSON object, or if it
+// contains an empty object. The corpus has no empty maps or structs, so {} means that a value
+// lost its content, like errors encoded by json.Marshal.
+func assertValidRecords(t *testing.T, out string) {
+	t.Helper()
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Errorf("Record is not a valid JSON object: %v\n%s", err, line)
+			continue
+		}
+		if path, ok := findEmptyObject(record, ""); ok {
+			t.Errorf("Value %s is an empty object, it was probably encoded lossy:\n%s", path, line)
+		}
+	}
+}
+
+// findEmptyObject returns the path of the first empty object in value.
+func findEmptyObject(value interface{}, path string) (string, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return path, true
+		}
+		for key, child := range v {
+			if p, ok := findEmptyObject(child, path+"."+key); ok {
+				return p, true
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			if p, ok := findEmptyObject(child, path+"["+strconv.Itoa(i)+"]"); ok {
+				return p, true
+			}
+		}
+	}
+	return "", false
+}
+
+type goldenStringer struct{}
+
+func (goldenStringer) String() string { return "stringer \"quoted\"" }
+
+type goldenMarshaler struct{}
+
+func (goldenMarshaler) MarshalJSON() ([]byte, error) {
+	return []byte(`{ "spaced" : [1, 2] }`), nil
+}
+
+// logGoldenCorpus writes records with every kind of value the encoder handles.
+func logGoldenCorpus(logger Logger) {
+	var nilCustomer *testCustomer
+	customer := testCustomer{
+		ID:       "c-1",
+		Name:     "Jane",
+		Password: "pw",
+		IBAN:     "DE89370400440532013000",
+		Email:    "jane@example.com",
+		Age:      42,
+		Address:  &testAddress{Street: "Main Street 1", City: "Berlin"},
+	}
+
+	// Value types
+	logger.Info("Primitives",
+		"string", "value",
+		"int", -1,
+		"int32", int32(-32),
+		"int64", int64(-64),
+		"uint", uint(1),
+		"uint64", uint64(18446744073709551615),
+		"float32", float32(1.5),
+		"float64", 3.14159265,
+		"bool", true,
+		"nil", nil,
+	)
+	logger.Info("Standard types",
+		"time", goldenTime.Add(time.Hour),
+		"duration", 1500*time.Millisecond,
+		"bytes", []byte("bytes \"quoted\""),
+		"raw", RawJSON(`{"raw": [true, null]}`),
+		"error", errors.New("error \"quoted\""),
+		"ip", net.IPv4(192, 168, 0, 1),
+		"stringer", goldenStringer{},
+		"marshaler", goldenMarshaler{},
+		"nil_pointer", nilCustomer,
+		"lazy", Lazy(func() interface{} { return "computed" }),
+	)
+	logger.InfoF("Typed fields",
+		String("string", "value"),
+		Int("int", 1),
+		Int64("int64", 64),
+		Uint64("uint64", 64),
+		Float64("float64", 0.5),
+		Bool("bool", false),
+		Duration("duration", time.Minute),
+		Time("time", goldenTime),
+		Err(errors.New("failed")),
+		Object("object", []int{1, 2, 3}),
+	)
+	logger.InfoEvent().Str("str", "value").Int("int", 1).Dur("dur", time.Second).Err(nil).Msg("Event")
+	logger.Infof("Printf %s %d %v", "args", 42, []string{"a"})
+
+	// Values that JSON cannot represent directly or that fail to encode
+	logger.Info("Special values",
+		"nan", math.NaN(),
+		"inf", math.Inf(1),
+		"neg_inf", float32(math.Inf(-1)),
+		"zero_time", time.Time{},
+		"far_time", time.Date(3000, 1, 2, 3, 4, 5, 6, time.UTC),
+		"wrapped_error", fmt.Errorf("wrapped: %w", errors.New("cause")),
+		"failing_marshaler", testFailingMarshaler{},
+		"failing_text_marshaler", testFailingTextMarshaler{},
+		"nested_failing", map[string]interface{}{"value": testFailingMarshaler{}},
+		"panicking", testPanicStringer{},
+	)
+	logger.InfoF("Special typed fields",
+		Float64("nan", math.NaN()),
+		Float64("neg_inf", math.Inf(-1)),
+		Time("zero_time", time.Time{}),
+		Time("far_time", time.Date(1500, 1, 2, 3, 4, 5, 6, time.UTC)),
+		Object("failing", testFailingMarshaler{}),
+	)
+
+	// Escaping
+	logger.Info("Quotes \" and backslashes \\ in the message", "path", `C:\temp\"x"`)
+	logger.Info("Control characters \n\r\t\x00\x1f\x7f", "control", "\b\f\n\r\t\x01")
+	logger.Info("Unicode", "umlauts", "äöü ß", "emoji", "😀", "separators", "\u2028\u2029", "html", "<a href=\"x\">&</a>")
+	logger.Info("Invalid UTF-8", "invalid", "a\xffb\xc3", "truncated", "\xe2\x82")
+	logger.Info("Keys", "key \"quoted\"", 1, "key\nnewline", 2, 42, "non-string key", "odd")
+
+	// Values crossing the buffer of the StackWriter
+	logger.Info(makeString(bufSize - 40))
+	logger.Info("Long value", "long", makeString(3*bufSize))
+	logger.Info("Escape at buffer boundary", "escaped", makeString(bufSize-90)+strings.Repeat("\"\n", 40))
+	logger.Info("Long bytes", "bytes", []byte(makeString(bufSize)+"\n\""))
+
+	// Nested values
+	logger.Info("Nested",
+		"map", map[string]interface{}{"b": map[string]interface{}{"c": []interface{}{1, "two", nil, map[string]int{"d": 4}}}, "a": true},
+		"slice", [][]string{{"a", "b"}, {}, nil},
+		"struct", customer,
+		"raw_nested", RawJSON(`{"a": {"b": {"c": [1, {"d": "e"}]}}}`),
+	)
+	logger.Info("Nested tagged structs",
+		"order", testOrder{ID: "o-1", Customer: customer, Shipping: []*testAddress{customer.Address, nil}},
+		"customers", []testCustomer{customer},
+		"by_id", map[string]testCustomer{"c-1": customer},
+	)
+	logger.Named("payment").Named("card").Info("Nested loggers", "customer", &customer)
+
+	// Levels with caller and stack trace
+	logger.Trace("Trace")
+	logger.Debug("Debug")
+	logger.Warn("Warn")
+	logger.Error("Error", "error", errors.New("failed"))
+	logger.Log(LevelError+1, "Custom level")
+	logger.Log(LevelFatal, "Fatal")
+}
+
+func TestGolden_Corpus(t *testing.T) {
+	schemas := map[string]Schema{
+		"default": DefaultSchema,
+		"ecs":     ECSSchema,
+		"gcp":     GCPSchema,
+		"datadog": DatadogSchema,
+	}
+	for name, schema := range schemas {
+		t.Run(name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			logGoldenCorpus(NewWithWriter(LvlTrace, out, WithSchema(schema), WithClock(NewFakeClock(goldenTime))))
+
+			assertValidRecords(t, out.String())
+			assertGolden(t, "records_"+name, normalizeRecords(out.String()))
+		})
+	}
+}
+
+func TestGolden_Options(t *testing.T) {
+	tests := map[string][]Option{
+		"unix_millis": {WithTimeFormat(TimeUnixMilli)},
+		"time_none":   {WithTimeFormat(TimeNone)},
+		"location":    {WithTimeFormat(TimeRFC3339Nano), WithTimeLocation(time.FixedZone("CET", 3600))},
+		"static":      {WithService("checkout", "1.2.3", "prod"), WithFields(String("region", "eu"))},
+		"redacted":    {WithRedactor(NewRedactor(RedactorConfig{Keys: []string{"IBAN", "email", "password"}}))},
+		"no_caller":   {WithoutCaller(), WithoutStackTrace()},
+	}
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			opts = append([]Option{WithClock(NewFakeClock(goldenTime))}, opts...)
+			logger := NewWithWriter(LvlInfo, out, opts...)
+			logger.Info("Options", "email", "jane@example.com", "password", "secret", "customer", testCustomer{IBAN: "DE89370400440532013000"})
+			logger.Error("Failed")
+
+			assertValidRecords(t, out.String())
+			assertGolden(t, "options_"+name, normalizeRecords(out.String()))
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package logger
+
+// Entry is a log record that has not been encoded yet. It is passed to every Hook.
+type Entry struct {
+	Level         string
+	Logger        string // name of the logger, see Named
+	Message       string
+	KeysAndValues []interface{}
+	owned         bool
+}
+
+// Hook is called for every record before it is encoded, in the order the hooks were
+// installed. A hook can enrich or modify the entry, e.g. by calling Add or Set. Returning
+// false drops the record, the remaining hooks are not called in that case.
+//
+// The entry and its KeysAndValues slice are only valid during the call, hooks must not
+// keep references to them. Use Add and Set instead of writing to KeysAndValues directly,
+// because the slice may be owned by the caller of the log method.
+//
+// Fields of ErrorF, InfoF etc. are appended to KeysAndValues with the Field as value.
+//
+// Logging stays zero-alloc as long as no hooks are installed. Installing a hook costs one
+// allocation per record, plus one more for the first Add or Set call.
+type Hook func(e *Entry) (keep bool)
+
+// WithHooks installs hooks that run before each record is encoded.
+func WithHooks(hooks ...Hook) Option {
+	return func(l *instance) {
+		l.hooks = append(l.hooks, hooks...)
+	}
+}
+
+// Add appends key/value pairs to the entry.
+func (e *Entry) Add(keysAndValues ...interface{}) {
+	e.own(len(keysAndValues))
+	e.KeysAndValues = append(e.KeysAndValues, keysAndValues...)
+}
+
+// Set replaces the value of the first pair with the given key, or appends the pair if
+// the key is not present.
+func (e *Entry) Set(key string, value interface{}) {
+	for i := 0; i+1 < len(e.KeysAndValues); i += 2 {
+		if k, ok := e.KeysAndValues[i].(string); ok && k == key {
+			e.own(0)
+			e.KeysAndValues[i+1] = value
+			return
+		}
+	}
+	e.Add(key, value)
+}
+
+// Get returns the value of the first pair with the given key.
+func (e *Entry) Get(key string) (value interface{}, ok bool) {
+	for i := 0; i+1 < len(e.KeysAndValues); i += 2 {
+		if k, ok := e.KeysAndValues[i].(string); ok && k == key {
+			return e.KeysAndValues[i+1], true
+		}
+	}
+	return nil, false
+}
+
+// own copies KeysAndValues before the first modification, so the caller's slice is never changed.
+func (e *Entry) own(extra int) {
+	if e.owned {
+		return
+	}
+	kv := make([]interface{}, len(e.KeysAndValues), len(e.KeysAndValues)+extra)
+	copy(kv, e.KeysAndValues)
+	e.KeysAndValues = kv
+	e.owned = true
+}
+
+func (l *instance) runHooks(level string, message string, fields []Field, keysAndValues []interface{}) (string, []interface{}, bool) {
+	e := &Entry{
+		Level:         level,
+		Logger:        l.name,
+		Message:       message,
+		KeysAndValues: keysAndValues,
+	}
+	// Typed fields are passed to hooks as key/value pairs, the Field itself is the value.
+	for _, f := range fields {
+		e.Add(f.Key, f)
+	}
+	for _, hook := range l.hooks {
+		if !hook(e) {
+			return "", nil, false
+		}
+	}
+	return e.Message, e.KeysAndValues, true
+}
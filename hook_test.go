@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLogger_Hooks(t *testing.T) {
+	errorCount := 0
+	out := bytes.NewBufferString("")
+	logger := NewWithWriter(LvlInfo, out, WithHooks(
+		func(e *Entry) bool {
+			return !strings.HasPrefix(e.Message, "noisy")
+		},
+		func(e *Entry) bool {
+			if e.Level == LvlError {
+				errorCount++
+			}
+			return true
+		},
+		func(e *Entry) bool {
+			e.Add("env", "prod")
+			if v, ok := e.Get("password"); ok && v != nil {
+				e.Set("password", "***")
+			}
+			return true
+		},
+	))
+
+	kv := []interface{}{"user", "alice", "password", "secret"}
+	logger.Info("noisy third-party message")
+	logger.Error("Login failed", kv...)
+
+	if errorCount != 1 {
+		t.Errorf("errorCount = %d, want 1", errorCount)
+	}
+	if kv[3] != "secret" {
+		t.Errorf("Hook modified the caller's slice: %v", kv)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 record, got %d:\n%s", len(lines), out.String())
+	}
+
+	actual := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(lines[0]), &actual); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"message":  "Login failed",
+		"user":     "alice",
+		"password": "***",
+		"env":      "prod",
+	}
+	for k, v := range want {
+		if actual[k] != v {
+			t.Errorf("Field %s = %v, want %v", k, actual[k], v)
+		}
+	}
+}
+
+func TestLogger_Hooks_CallerInfo(t *testing.T) {
+	out := bytes.NewBufferString("")
+	logger := NewWithWriter(LvlInfo, out, WithHooks(func(e *Entry) bool { return true }))
+	logger.Warn("with hook")
+
+	if !strings.Contains(out.String(), "hook_test.go") {
+		t.Errorf("Caller info does not point to the test file: %s", out.String())
+	}
+}
+
+func TestLogger_NoHooks_ZeroAlloc(t *testing.T) {
+	logger := NewWithWriter(LvlInfo, io.Discard, WithHooks())
+	allocs := testing.AllocsPerRun(1, func() {
+		logger.Info("Lorem ipsum",
+			"int", 1,
+			"bool", true)
+	})
+
+	if allocs > 0.0 {
+		t.Errorf("Allocs detected! Want 0 allocs, got %f", allocs)
+	}
+}
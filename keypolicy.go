@@ -0,0 +1,153 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// BadKey is written as key for values whose key is neither a string nor a fmt.Stringer.
+	BadKey = "!BADKEY"
+	// ExtraValue is written as key for a trailing key/value element without value.
+	ExtraValue = "EXTRA_VALUE"
+)
+
+// KeyPolicy configures how invalid, duplicate and reserved keys are handled. The zero value
+// is lenient: a trailing key without value is dropped, invalid keys are written as
+// "INVALID_KEY_...", and duplicate or reserved keys are written as they are.
+type KeyPolicy struct {
+	// ReportBadKeys writes a trailing element without value as ExtraValue field and values
+	// with an invalid key as BadKey field.
+	ReportBadKeys bool
+	// ReservedKeyPrefix is prepended to keys that collide with the keys of the record
+	// itself, i.e. ts, level, message, logger, caller_func, caller_file and stacktrace, or
+	// the keys of the Schema.
+	ReservedKeyPrefix string
+	// Deduplicate writes only the last value of keys that occur multiple times.
+	Deduplicate bool
+	// Panic panics on odd key/value counts, invalid, duplicate and reserved keys.
+	// Meant to find mistakes in tests.
+	Panic bool
+}
+
+// WithKeyPolicy sets how invalid, duplicate and reserved keys are handled.
+func WithKeyPolicy(policy KeyPolicy) Option {
+	return func(l *instance) {
+		l.keyPolicy = policy
+	}
+}
+
+// writeFields writes the key/value pairs and typed fields of a record.
+func (l *instance) writeFields(sw *StackWriter, fields []Field, keysAndValues []interface{}) {
+	policy := &l.keyPolicy
+	fn := len(keysAndValues)
+	for i := 0; i+1 < fn; i += 2 {
+		key := noescape_interface(&keysAndValues[i])
+		if policy.Deduplicate {
+			if k, ok := key.(string); ok && isKeyRepeated(k, keysAndValues[i+2:], fields) {
+				continue
+			}
+		}
+
+		sw.Write(", ")
+		l.writeKey(sw, key)
+		sw.Write(": ")
+		l.encoder.encodeField(sw, key, noescape_interface(&keysAndValues[i+1]))
+	}
+
+	if fn%2 == 1 && policy.ReportBadKeys {
+		sw.Write(", ")
+		sw.WriteJSONString(ExtraValue)
+		sw.Write(": ")
+		l.encoder.encodeValue(sw, noescape_interface(&keysAndValues[fn-1]))
+	}
+
+	for i := range fields {
+		f := noescape_fieldptr(&fields[i])
+		if policy.Deduplicate && isKeyRepeated(f.Key, nil, fields[i+1:]) {
+			continue
+		}
+
+		sw.Write(", ")
+		l.writeKey(sw, f.Key)
+		sw.Write(": ")
+		l.encoder.encodeTypedField(sw, f)
+	}
+}
+
+func (l *instance) writeKey(sw *StackWriter, key interface{}) {
+	switch k := key.(type) {
+	case string:
+		if l.keyPolicy.ReservedKeyPrefix != "" && l.schema.isReserved(k) {
+			sw.Write("\"")
+			sw.WriteEscaped(l.keyPolicy.ReservedKeyPrefix)
+			sw.WriteEscaped(noescape_string(&k))
+			sw.Write("\"")
+			return
+		}
+	case fmt.Stringer:
+	default:
+		if l.keyPolicy.ReportBadKeys {
+			sw.WriteJSONString(BadKey)
+			return
+		}
+	}
+	encodeKey(sw, key)
+}
+
+// isKeyRepeated returns true if key is used again in the remaining pairs or fields.
+func isKeyRepeated(key string, keysAndValues []interface{}, fields []Field) bool {
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		if k, ok := keysAndValues[i].(string); ok && k == key {
+			return true
+		}
+	}
+	for i := range fields {
+		if fields[i].Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// checkKeys panics if the key/value pairs or fields violate the key policy.
+func (l *instance) checkKeys(fields []Field, keysAndValues []interface{}) {
+	var problems []string
+	if len(keysAndValues)%2 == 1 {
+		problems = append(problems, fmt.Sprintf("odd number of key/value elements, %v has no value", keysAndValues[len(keysAndValues)-1]))
+	}
+
+	// Static fields are written first, see WithFields.
+	keys := make([]string, 0, len(l.fields)+len(keysAndValues)/2+len(fields))
+	for _, f := range l.fields {
+		keys = append(keys, f.Key)
+	}
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		switch k := keysAndValues[i].(type) {
+		case string:
+			keys = append(keys, k)
+		case fmt.Stringer:
+		default:
+			problems = append(problems, fmt.Sprintf("invalid key %v of type %T", k, k))
+		}
+	}
+	for _, f := range fields {
+		keys = append(keys, f.Key)
+	}
+
+	for i, k := range keys {
+		if l.schema.isReserved(k) {
+			problems = append(problems, fmt.Sprintf("key %q is reserved", k))
+		}
+		for _, other := range keys[:i] {
+			if k == other {
+				problems = append(problems, fmt.Sprintf("duplicate key %q", k))
+				break
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		panic("logger: " + strings.Join(problems, ", "))
+	}
+}
@@ -0,0 +1,127 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestLogger_KeyPolicy(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        KeyPolicy
+		keysAndValues []interface{}
+		fields        []Field
+		wantSuffix    string
+	}{{
+		name:          "Lenient default drops trailing key",
+		keysAndValues: []interface{}{"a", 1, "b"},
+		wantSuffix:    `"message": "msg", "a": 1}`,
+	}, {
+		name:          "Lenient default writes invalid key",
+		keysAndValues: []interface{}{1, "x"},
+		wantSuffix:    `"message": "msg", "INVALID_KEY_1": "x"}`,
+	}, {
+		name:          "Report extra value",
+		policy:        KeyPolicy{ReportBadKeys: true},
+		keysAndValues: []interface{}{"a", 1, "b"},
+		wantSuffix:    `"message": "msg", "a": 1, "EXTRA_VALUE": "b"}`,
+	}, {
+		name:          "Report bad key",
+		policy:        KeyPolicy{ReportBadKeys: true},
+		keysAndValues: []interface{}{1, "x", &testStringer{value: "stringer"}, "y"},
+		wantSuffix:    `"message": "msg", "!BADKEY": "x", "stringer": "y"}`,
+	}, {
+		name:          "Prefix reserved keys",
+		policy:        KeyPolicy{ReservedKeyPrefix: "fields."},
+		keysAndValues: []interface{}{"message", "user message", "level", 2},
+		fields:        []Field{String("ts", "now")},
+		wantSuffix:    `"message": "msg", "fields.message": "user message", "fields.level": 2, "fields.ts": "now"}`,
+	}, {
+		name:          "Deduplicate keys, last wins",
+		policy:        KeyPolicy{Deduplicate: true},
+		keysAndValues: []interface{}{"a", 1, "b", 2, "a", 3},
+		fields:        []Field{Int("b", 4)},
+		wantSuffix:    `"message": "msg", "a": 3, "b": 4}`,
+	},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := bytes.NewBufferString("")
+			logger := NewWithWriter(LvlInfo, out, WithKeyPolicy(tt.policy))
+			logger.log(LevelInfo, "msg", nil, tt.fields, tt.keysAndValues)
+
+			got := strings.TrimSuffix(out.String(), "\n")
+			if !strings.HasSuffix(got, tt.wantSuffix) {
+				t.Errorf("Written string does not match.\nWant suffix: %s\nGot: %s", tt.wantSuffix, got)
+			}
+			if !json.Valid([]byte(got)) {
+				t.Errorf("Output is not valid JSON: %s", got)
+			}
+		})
+	}
+}
+
+func TestLogger_KeyPolicy_Panic(t *testing.T) {
+	tests := []struct {
+		name          string
+		keysAndValues []interface{}
+		fields        []Field
+		wantPanic     string
+	}{{
+		name:          "Odd count",
+		keysAndValues: []interface{}{"a", 1, "b"},
+		wantPanic:     "odd number of key/value elements",
+	}, {
+		name:          "Invalid key",
+		keysAndValues: []interface{}{1, 1},
+		wantPanic:     "invalid key 1 of type int",
+	}, {
+		name:          "Duplicate key",
+		keysAndValues: []interface{}{"a", 1},
+		fields:        []Field{Int("a", 2)},
+		wantPanic:     `duplicate key "a"`,
+	}, {
+		name:          "Reserved key",
+		keysAndValues: []interface{}{"caller_func", 1},
+		wantPanic:     `key "caller_func" is reserved`,
+	}, {
+		name:          "Valid keys",
+		keysAndValues: []interface{}{"a", 1, "b", 2},
+	},
+	}
+	dropAll := WithHooks(func(e *Entry) bool { return false })
+	for _, tt := range tests {
+		for _, dropped := range []bool{false, true} {
+			name := tt.name
+			opts := []Option{WithKeyPolicy(KeyPolicy{Panic: true})}
+			if dropped {
+				// The keys are checked even if a hook drops the record.
+				name += " dropped by hook"
+				opts = append(opts, dropAll)
+			}
+			t.Run(name, func(t *testing.T) {
+				out := bytes.NewBufferString("")
+				logger := NewWithWriter(LvlInfo, out, opts...)
+
+				defer func() {
+					r := recover()
+					if tt.wantPanic == "" {
+						if r != nil {
+							t.Errorf("Unexpected panic: %v", r)
+						}
+						return
+					}
+					if r == nil || !strings.Contains(r.(string), tt.wantPanic) {
+						t.Errorf("Panic = %v, want %s", r, tt.wantPanic)
+					}
+					if out.Len() > 0 {
+						t.Errorf("Record was written: %s", out.String())
+					}
+				}()
+				logger.log(LevelInfo, "msg", nil, tt.fields, tt.keysAndValues)
+			})
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package logger
+
+import "fmt"
+
+// LazyValue is a value that is expensive to compute. LogValue is only called if the record
+// is written, at most once per record and on the goroutine that called the log method,
+// before the logger is locked. So it is safe to log from within LogValue.
+type LazyValue interface {
+	LogValue() interface{}
+}
+
+// Lazy wraps fn into a LazyValue:
+//
+//	log.Debug("Request", "body", logger.Lazy(func() interface{} { return dump(req) }))
+func Lazy(fn func() interface{}) LazyValue {
+	return lazyFunc(fn)
+}
+
+type lazyFunc func() interface{}
+
+func (fn lazyFunc) LogValue() interface{} {
+	return fn()
+}
+
+// maxLazyDepth limits how often a LazyValue may return another LazyValue.
+const maxLazyDepth = 10
+
+// resolveLazyValues replaces lazy values by their results. The slices are only copied if
+// they contain lazy values. Typed fields that hooks have turned into key/value pairs are
+// resolved too.
+func resolveLazyValues(fields []Field, keysAndValues []interface{}) ([]Field, []interface{}) {
+	for i := 1; i < len(keysAndValues); i += 2 {
+		if !isLazy(keysAndValues[i]) {
+			continue
+		}
+		resolved := make([]interface{}, len(keysAndValues))
+		copy(resolved, keysAndValues)
+		for j := i; j < len(resolved); j += 2 {
+			if f, ok := resolved[j].(Field); ok && f.Type == ObjectType {
+				f.iface = resolveLazy(f.iface)
+				resolved[j] = f
+			} else {
+				resolved[j] = resolveLazy(resolved[j])
+			}
+		}
+		keysAndValues = resolved
+		break
+	}
+
+	for i := range fields {
+		if !isLazyField(fields[i]) {
+			continue
+		}
+		resolved := make([]Field, len(fields))
+		copy(resolved, fields)
+		for j := i; j < len(resolved); j++ {
+			if resolved[j].Type == ObjectType {
+				resolved[j].iface = resolveLazy(resolved[j].iface)
+			}
+		}
+		fields = resolved
+		break
+	}
+
+	return fields, keysAndValues
+}
+
+// isLazy returns true if value is a LazyValue or a typed field of one.
+func isLazy(value interface{}) bool {
+	if f, ok := value.(Field); ok {
+		return isLazyField(f)
+	}
+	_, ok := value.(LazyValue)
+	return ok
+}
+
+func isLazyField(f Field) bool {
+	_, ok := f.iface.(LazyValue)
+	return ok && f.Type == ObjectType
+}
+
+// resolveLazy evaluates value if it is a LazyValue. A panic in LogValue results in
+// a "<PANIC: ...>" value.
+func resolveLazy(value interface{}) (resolved interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			resolved = fmt.Sprintf("<PANIC: %v>", r)
+		}
+	}()
+
+	for depth := 0; depth < maxLazyDepth; depth++ {
+		lazy, ok := value.(LazyValue)
+		if !ok || isNilPointer(value) {
+			return value
+		}
+		value = lazy.LogValue()
+	}
+	return value
+}
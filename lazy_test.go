@@ -0,0 +1,110 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger_Lazy(t *testing.T) {
+	calls := 0
+	out := bytes.NewBufferString("")
+	logger := NewWithWriter(LvlInfo, out)
+	payload := Lazy(func() interface{} {
+		calls++
+		// Logging from within the lazy value must not deadlock.
+		logger.Info("evaluating")
+		return map[string]int{"size": 3}
+	})
+
+	logger.Debug("disabled", "payload", payload)
+	logger.DebugF("disabled", Object("payload", payload))
+	logger.DebugEvent().Interface("payload", payload).Msg("disabled")
+	if calls != 0 {
+		t.Fatalf("Lazy value of disabled level was evaluated %d times", calls)
+	}
+
+	logger.Info("enabled", "payload", payload)
+	if calls != 1 {
+		t.Errorf("Lazy value was evaluated %d times, want 1", calls)
+	}
+	logger.InfoF("enabled", Object("payload", payload))
+	if calls != 2 {
+		t.Errorf("Lazy value was evaluated %d times, want 2", calls)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 records, got %d:\n%s", len(lines), out.String())
+	}
+	for _, i := range []int{1, 3} {
+		if !strings.HasSuffix(lines[i], `"payload": {"size":3}}`) {
+			t.Errorf("Lazy value not written: %s", lines[i])
+		}
+	}
+}
+
+func TestLogger_Lazy_DroppedByHook(t *testing.T) {
+	calls := 0
+	logger := NewWithWriter(LvlInfo, bytes.NewBufferString(""), WithHooks(func(e *Entry) bool {
+		return false
+	}))
+	logger.Info("dropped", "payload", Lazy(func() interface{} {
+		calls++
+		return 1
+	}))
+	if calls != 0 {
+		t.Errorf("Lazy value of dropped record was evaluated %d times", calls)
+	}
+}
+
+func TestLogger_Lazy_Panic(t *testing.T) {
+	out := bytes.NewBufferString("")
+	logger := NewWithWriter(LvlInfo, out)
+	logger.Info("panic", "payload", Lazy(func() interface{} {
+		panic("boom")
+	}), "nested", Lazy(func() interface{} {
+		return Lazy(func() interface{} { return "value" })
+	}))
+
+	want := `"payload": "<PANIC: boom>", "nested": "value"}`
+	if !strings.HasSuffix(strings.TrimSpace(out.String()), want) {
+		t.Errorf("Written string does not match.\nWant suffix: %s\nGot: %s", want, out.String())
+	}
+}
+
+func TestLogger_Lazy_LogFromLazyWithHook(t *testing.T) {
+	out := bytes.NewBufferString("")
+	logger := NewWithWriter(LvlInfo, out, WithHooks(func(e *Entry) bool {
+		return true
+	}))
+	payload := Lazy(func() interface{} {
+		// Logging from within the lazy value must not deadlock, also if hooks have turned
+		// the typed field into a key/value pair.
+		logger.Info("evaluating")
+		return 3
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		logger.InfoF("enabled", Object("payload", payload))
+		logger.InfoEvent().Interface("payload", payload).Msg("enabled")
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Logging from within a lazy value deadlocked")
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 records, got %d:\n%s", len(lines), out.String())
+	}
+	for _, i := range []int{1, 3} {
+		if !strings.HasSuffix(lines[i], `"payload": 3}`) {
+			t.Errorf("Lazy value not written: %s", lines[i])
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package logger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Level is the severity of a record. Higher values are more severe. The gaps between the
+// predefined levels leave room for custom levels, see RegisterLevel.
+type Level int
+
+const (
+	LevelTrace Level = -8
+	LevelDebug Level = -4
+	LevelInfo  Level = 0
+	LevelWarn  Level = 4
+	LevelError Level = 8
+	LevelFatal Level = 12
+	LevelPanic Level = 16
+)
+
+var levelRegistry = struct {
+	sync.RWMutex
+	names  map[Level]string
+	levels map[string]Level
+}{
+	names:  map[Level]string{},
+	levels: map[string]Level{},
+}
+
+func init() {
+	for _, level := range []Level{LevelTrace, LevelDebug, LevelInfo, LevelWarn, LevelError, LevelFatal, LevelPanic} {
+		levelRegistry.names[level] = level.String()
+		levelRegistry.levels[level.String()] = level
+	}
+}
+
+// RegisterLevel adds a custom level, e.g. AUDIT between ERROR and FATAL:
+//
+//	var LevelAudit = logger.MustRegisterLevel("AUDIT", logger.LevelError+2)
+//
+// The name is case insensitive and written in upper case. A name or severity can only be
+// registered once. Register levels during initialization, before they are logged.
+func RegisterLevel(name string, level Level) error {
+	name = strings.ToUpper(name)
+	if name == "" || strings.ContainsAny(name, "+- \t\r\n\"") {
+		return fmt.Errorf("invalid level name: %q", name)
+	}
+
+	levelRegistry.Lock()
+	defer levelRegistry.Unlock()
+	if other, ok := levelRegistry.names[level]; ok {
+		return fmt.Errorf("level %d is already registered as %s", level, other)
+	}
+	if _, ok := levelRegistry.levels[name]; ok {
+		return fmt.Errorf("level %s is already registered", name)
+	}
+	levelRegistry.names[level] = name
+	levelRegistry.levels[name] = level
+	return nil
+}
+
+// MustRegisterLevel registers a custom level and returns it, or panics.
+func MustRegisterLevel(name string, level Level) Level {
+	if err := RegisterLevel(name, level); err != nil {
+		panic(err)
+	}
+	return level
+}
+
+// ParseLevel returns the level for a name like "info", or a name with an offset like
+// "INFO+2". Custom levels are included.
+func ParseLevel(s string) (Level, error) {
+	name, offset := s, 0
+	if i := strings.IndexAny(s, "+-"); i > 0 {
+		var err error
+		if offset, err = strconv.Atoi(s[i:]); err != nil {
+			return 0, fmt.Errorf("invalid level: %s", s)
+		}
+		name = s[:i]
+	}
+
+	levelRegistry.RLock()
+	level, ok := levelRegistry.levels[strings.ToUpper(name)]
+	levelRegistry.RUnlock()
+	if !ok {
+		return 0, fmt.Errorf("invalid level: %s", s)
+	}
+	return level + Level(offset), nil
+}
+
+// String returns the name of the level. Levels without name are written relative to the
+// next lower predefined level, e.g. "ERROR+2".
+func (l Level) String() string {
+	switch l {
+	case LevelTrace:
+		return LvlTrace
+	case LevelDebug:
+		return LvlDebug
+	case LevelInfo:
+		return LvlInfo
+	case LevelWarn:
+		return LvlWarn
+	case LevelError:
+		return LvlError
+	case LevelFatal:
+		return LvlFatal
+	case LevelPanic:
+		return LvlPanic
+	}
+
+	levelRegistry.RLock()
+	name, ok := levelRegistry.names[l]
+	levelRegistry.RUnlock()
+	if ok {
+		return name
+	}
+
+	base := LevelTrace
+	for _, predefined := range []Level{LevelPanic, LevelFatal, LevelError, LevelWarn, LevelInfo, LevelDebug} {
+		if l > predefined {
+			base = predefined
+			break
+		}
+	}
+	offset := int(l - base)
+	if offset < 0 {
+		return base.String() + strconv.Itoa(offset)
+	}
+	return base.String() + "+" + strconv.Itoa(offset)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, see ParseLevel.
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// Set implements flag.Value, see ParseLevel.
+func (l *Level) Set(s string) error {
+	return l.UnmarshalText([]byte(s))
+}
@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]Level{
+		"trace":   LevelTrace,
+		"DEBUG":   LevelDebug,
+		"Info":    LevelInfo,
+		"WARN":    LevelWarn,
+		"error":   LevelError,
+		"FATAL":   LevelFatal,
+		"panic":   LevelPanic,
+		"INFO+2":  LevelInfo + 2,
+		"error-1": LevelError - 1,
+	}
+	for input, expected := range tests {
+		level, err := ParseLevel(input)
+		if err != nil || level != expected {
+			t.Errorf("ParseLevel(%q) = %d, %v, Expected %d", input, level, err, expected)
+		}
+	}
+
+	for _, input := range []string{"", "verbose", "INFO+", "+2", "INFO+x"} {
+		if _, err := ParseLevel(input); err == nil {
+			t.Errorf("ParseLevel(%q) should fail", input)
+		}
+	}
+}
+
+func TestLevel_String(t *testing.T) {
+	tests := map[Level]string{
+		LevelTrace:     "TRACE",
+		LevelInfo:      "INFO",
+		LevelPanic:     "PANIC",
+		LevelInfo + 1:  "INFO+1",
+		LevelError + 3: "ERROR+3",
+		LevelTrace - 2: "TRACE-2",
+		LevelPanic + 4: "PANIC+4",
+	}
+	for level, expected := range tests {
+		if level.String() != expected {
+			t.Errorf("String of %d is incorrect, Expected %s, Actual %s", level, expected, level.String())
+		}
+		parsed, err := ParseLevel(level.String())
+		if err != nil || parsed != level {
+			t.Errorf("ParseLevel(%q) = %d, %v, Expected %d", level.String(), parsed, err, level)
+		}
+	}
+}
+
+func TestLevel_MarshalText(t *testing.T) {
+	type config struct {
+		Level Level `json:"level"`
+	}
+
+	var c config
+	if err := json.Unmarshal([]byte(`{"level": "debug"}`), &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Level != LevelDebug {
+		t.Errorf("Level is incorrect, Expected %d, Actual %d", LevelDebug, c.Level)
+	}
+
+	out, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `{"level":"DEBUG"}` {
+		t.Errorf("JSON is incorrect: %s", out)
+	}
+
+	if err := json.Unmarshal([]byte(`{"level": "loud"}`), &c); err == nil {
+		t.Errorf("Unmarshal of invalid level should fail")
+	}
+}
+
+func TestLevel_Flag(t *testing.T) {
+	level := LevelInfo
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.Var(&level, "level", "log level")
+
+	if err := flags.Parse([]string{"-level", "warn"}); err != nil {
+		t.Fatal(err)
+	}
+	if level != LevelWarn {
+		t.Errorf("Level is incorrect, Expected %d, Actual %d", LevelWarn, level)
+	}
+}
+
+func TestRegisterLevel(t *testing.T) {
+	audit := LevelError + 2
+	if err := RegisterLevel("audit", audit); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		levelRegistry.Lock()
+		delete(levelRegistry.names, audit)
+		delete(levelRegistry.levels, "AUDIT")
+		levelRegistry.Unlock()
+	}()
+
+	if audit.String() != "AUDIT" {
+		t.Errorf("String is incorrect, Expected AUDIT, Actual %s", audit.String())
+	}
+	if level, err := GetValidLevel("Audit"); err != nil || level != "AUDIT" {
+		t.Errorf("GetValidLevel(Audit) = %q, %v", level, err)
+	}
+
+	for name, level := range map[string]Level{"AUDIT": LevelError + 3, "NOTICE": audit, "INFO": LevelInfo + 1, "A+B": 42, "": 43} {
+		if err := RegisterLevel(name, level); err == nil {
+			t.Errorf("RegisterLevel(%q, %d) should fail", name, level)
+		}
+	}
+
+	out := &bytes.Buffer{}
+	logger := NewWithWriter(LvlInfo, out)
+	logger.Log(audit, "User deleted", "user", "jane")
+	if !strings.Contains(out.String(), `"level": "AUDIT", "message": "User deleted", "user": "jane", "caller_func"`) {
+		t.Errorf("Record is incorrect: %s", out.String())
+	}
+}
+
+func TestLogger_Enabled(t *testing.T) {
+	tests := []struct {
+		level    string
+		enabled  []Level
+		disabled []Level
+	}{
+		{LvlTrace, []Level{LevelTrace, LevelDebug - 1, LevelInfo}, []Level{LevelTrace - 1}},
+		{LvlDebug, []Level{LevelDebug, LevelInfo}, []Level{LevelTrace, LevelDebug - 1}},
+		{LvlInfo, []Level{LevelInfo, LevelError}, []Level{LevelDebug, LevelInfo - 1}},
+		{LvlError, []Level{LevelInfo, LevelWarn}, []Level{LevelDebug}},
+	}
+	for _, tt := range tests {
+		logger := NewWithWriter(tt.level, &bytes.Buffer{})
+		for _, level := range tt.enabled {
+			if !logger.Enabled(level) {
+				t.Errorf("%s logger: %s should be enabled", tt.level, level)
+			}
+		}
+		for _, level := range tt.disabled {
+			if logger.Enabled(level) {
+				t.Errorf("%s logger: %s should be disabled", tt.level, level)
+			}
+		}
+	}
+}
+
+func TestLogger_Log(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := NewWithWriter(LvlInfo, out)
+
+	logger.Log(LevelDebug, "hidden")
+	logger.Log(LevelInfo+1, "Visible", "a", 1)
+	logger.Log(LevelFatal, "Not fatal")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 records, Actual %d: %s", len(lines), out.String())
+	}
+	if !strings.Contains(lines[0], `"level": "INFO+1", "message": "Visible", "a": 1}`) {
+		t.Errorf("Record is incorrect: %s", lines[0])
+	}
+	if !strings.Contains(lines[1], `"level": "FATAL"`) || !strings.Contains(lines[1], `"stacktrace": `) {
+		t.Errorf("Record is incorrect: %s", lines[1])
+	}
+}
@@ -3,6 +3,7 @@ package logger
 import (
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"runtime"
 	"strconv"
@@ -12,6 +13,8 @@ import (
 )
 
 const (
+	LvlPanic = "PANIC"
+	LvlFatal = "FATAL"
 	LvlError = "ERROR"
 	LvlWarn  = "WARN"
 	LvlInfo  = "INFO"
@@ -20,80 +23,273 @@ const (
 )
 
 type Logger interface {
+	// Fatal writes the record, flushes and closes the writer, runs the exit hooks and exits
+	// the process with status 1. The writers behind a MultiWriter or WatchConfigFile are
+	// closed too. Loggers created by Named share the writer, other loggers are not closed.
+	Fatal(msg string, keysAndValues ...interface{})
+	// Panic writes the record and panics with msg.
+	Panic(msg string, keysAndValues ...interface{})
 	Error(msg string, keysAndValues ...interface{})
 	Warn(msg string, keysAndValues ...interface{})
 	Info(msg string, keysAndValues ...interface{})
 	Debug(msg string, keysAndValues ...interface{})
 	Trace(msg string, keysAndValues ...interface{})
 
+	// ErrorF, WarnF, InfoF, DebugF and TraceF take typed fields instead of key/value pairs.
+	ErrorF(msg string, fields ...Field)
+	WarnF(msg string, fields ...Field)
+	InfoF(msg string, fields ...Field)
+	DebugF(msg string, fields ...Field)
+	TraceF(msg string, fields ...Field)
+
+	// Fatalf, Panicf, Errorf, Warnf, Infof, Debugf and Tracef format the message with
+	// fmt.Sprintf semantics. The message is only formatted if the level is enabled.
+	Fatalf(format string, args ...interface{})
+	Panicf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Debugf(format string, args ...interface{})
+	Tracef(format string, args ...interface{})
+
+	// ErrorEvent, WarnEvent, InfoEvent, DebugEvent and TraceEvent start a chained Event.
+	// Events of disabled levels are nil and cost almost nothing.
+	ErrorEvent() *Event
+	WarnEvent() *Event
+	InfoEvent() *Event
+	DebugEvent() *Event
+	TraceEvent() *Event
+
+	// Named returns a logger for a component, see LevelTable.
+	Named(name string) Logger
+
+	// Log writes a record with any level, including custom levels. Records with level FATAL
+	// or PANIC are only written, Log neither exits nor panics.
+	Log(level Level, msg string, keysAndValues ...interface{})
+	// Enabled returns true if records of the given level are written.
+	Enabled(level Level) bool
+
 	GetLevel() string
 	IsDebugEnabled() bool
 	IsTraceEnabled() bool
-	// Levels INFO, WARN and ERROR are always enabled.
+	// Levels INFO and above are always enabled.
+}
+
+// Option configures optional behaviour of a logger created by New or NewWithWriter.
+type Option func(l *instance)
+
+// levelOff is higher than every level that is logged.
+const levelOff = Level(math.MaxInt32)
+
+// WithCallerLevel writes caller_func and caller_file for records of the given level and
+// above. The default is WARN.
+func WithCallerLevel(level Level) Option {
+	return func(l *instance) {
+		l.callerLevel = level
+	}
+}
+
+// WithoutCaller never writes caller_func and caller_file.
+func WithoutCaller() Option {
+	return WithCallerLevel(levelOff)
+}
+
+// WithStackTraceLevel writes a stacktrace for records of the given level and above. The
+// default is FATAL.
+func WithStackTraceLevel(level Level) Option {
+	return func(l *instance) {
+		l.stackTraceLevel = level
+	}
+}
+
+// WithoutStackTrace never writes a stacktrace.
+func WithoutStackTrace() Option {
+	return WithStackTraceLevel(levelOff)
 }
 
 // New created a logger with given level
-func New(level string) Logger {
-	return NewWithWriter(level, os.Stdout)
+func New(level string, opts ...Option) Logger {
+	return NewWithWriter(level, os.Stdout, opts...)
 }
 
 // NewWithWriter created a logger with given level and writer
-func NewWithWriter(levelParam string, writer io.Writer) *instance {
-	level := MustGetValidLevel(levelParam)
-	return &instance{
-		level:        level,
-		writer:       writer,
-		debugEnabled: level == LvlDebug || level == LvlTrace,
-		traceEnabled: level == LvlTrace,
+func NewWithWriter(levelParam string, writer io.Writer, opts ...Option) *instance {
+	level, err := ParseLevel(levelParam)
+	if err != nil {
+		panic(err)
+	}
+	l := &instance{
+		level:           level,
+		writer:          writer,
+		schema:          defaultSchema,
+		callerLevel:     LevelWarn,
+		stackTraceLevel: LevelFatal,
+		exitFunc:        os.Exit,
+		mutex:           &sync.Mutex{},
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.timeFormat == TimeNone && l.schema.time != "" {
+		l.schema = l.schema.withoutTime()
 	}
+	if c, ok := l.clock.(*CoarseClock); ok && c.format == l.timeFormat && c.loc == l.timeLocation {
+		l.coarseClock = c
+	}
+	if l.format != FormatJSON {
+		l.writer = newFormatWriter(l.writer, l.format, l.schema)
+	}
+	l.encodeStaticFields()
+	return l
 }
 
 type instance struct {
+	// state caches the level resolved from levels and the generation of levels it was
+	// resolved for. It is accessed atomically and must be the first field for alignment.
+	state        uint64
 	writer       io.Writer
-	level        string
-	debugEnabled bool
-	traceEnabled bool
-	mutex        sync.Mutex
+	level        Level
+	name         string
+	levels       *LevelTable
+	sampler      *sampler
+	fields       []Field
+	staticFields string // fields, encoded once by NewWithWriter
+	// staticFieldParts are the encoded fields one by one, for KeyPolicy.Deduplicate.
+	staticFieldParts []string
+	hooks            []Hook
+	encoder          encoder
+	keyPolicy        KeyPolicy
+	schema           *schema
+	format           Format
+	timeFormat       TimeFormat
+	timeLocation     *time.Location
+	clock            Clock
+	coarseClock      *CoarseClock // set if clock keeps timestamps in the format of the logger
+	// callerLevel and stackTraceLevel are the minimum levels for caller info and stack traces.
+	callerLevel     Level
+	stackTraceLevel Level
+	exitFunc        func(code int)
+	// mutex is shared with the loggers created by Named, because they share the writer.
+	mutex *sync.Mutex
 }
 
 // GetLevel returns the level in a thread safe way
 func (l *instance) GetLevel() string {
-	return l.level
+	return l.effectiveLevel().String()
+}
+
+// Enabled returns true if records of the given level are written
+func (l *instance) Enabled(level Level) bool {
+	return l.enabled(level)
 }
 
 // IsDebugEnabled returns true if debug logging is enabled
 func (l *instance) IsDebugEnabled() bool {
-	return l.debugEnabled
+	return l.enabled(LevelDebug)
 }
 
 // IsTraceEnabled returns true if trace logging is enabled
 func (l *instance) IsTraceEnabled() bool {
-	return l.traceEnabled
+	return l.enabled(LevelTrace)
+}
+
+func (l *instance) Log(level Level, msg string, keysAndValues ...interface{}) {
+	if l.enabled(level) {
+		l.log(level, msg, nil, nil, keysAndValues)
+	}
+}
+
+func (l *instance) Fatal(msg string, keysAndValues ...interface{}) {
+	l.log(LevelFatal, msg, nil, nil, keysAndValues)
+	l.exit()
+}
+
+func (l *instance) Panic(msg string, keysAndValues ...interface{}) {
+	l.log(LevelPanic, msg, nil, nil, keysAndValues)
+	panic(msg)
 }
 
 func (l *instance) Error(msg string, keysAndValues ...interface{}) {
-	l.log(LvlError, msg, keysAndValues...)
+	l.log(LevelError, msg, nil, nil, keysAndValues)
 }
 
 func (l *instance) Warn(msg string, keysAndValues ...interface{}) {
-	l.log(LvlWarn, msg, keysAndValues...)
+	l.log(LevelWarn, msg, nil, nil, keysAndValues)
 }
 
 func (l *instance) Info(msg string, keysAndValues ...interface{}) {
-	l.log(LvlInfo, msg, keysAndValues...)
+	l.log(LevelInfo, msg, nil, nil, keysAndValues)
 }
 
 // Debug should be used for detailed logs
 func (l *instance) Debug(msg string, keysAndValues ...interface{}) {
-	if l.debugEnabled {
-		l.log(LvlDebug, msg, keysAndValues...)
+	if l.enabled(LevelDebug) {
+		l.log(LevelDebug, msg, nil, nil, keysAndValues)
 	}
 }
 
 // Trace should be used for dumps of payloads or similar
 func (l *instance) Trace(msg string, keysAndValues ...interface{}) {
-	if l.traceEnabled {
-		l.log(LvlTrace, msg, keysAndValues...)
+	if l.enabled(LevelTrace) {
+		l.log(LevelTrace, msg, nil, nil, keysAndValues)
+	}
+}
+
+func (l *instance) ErrorF(msg string, fields ...Field) {
+	l.log(LevelError, msg, nil, fields, nil)
+}
+
+func (l *instance) WarnF(msg string, fields ...Field) {
+	l.log(LevelWarn, msg, nil, fields, nil)
+}
+
+func (l *instance) InfoF(msg string, fields ...Field) {
+	l.log(LevelInfo, msg, nil, fields, nil)
+}
+
+func (l *instance) DebugF(msg string, fields ...Field) {
+	if l.enabled(LevelDebug) {
+		l.log(LevelDebug, msg, nil, fields, nil)
+	}
+}
+
+func (l *instance) TraceF(msg string, fields ...Field) {
+	if l.enabled(LevelTrace) {
+		l.log(LevelTrace, msg, nil, fields, nil)
+	}
+}
+
+func (l *instance) Fatalf(format string, args ...interface{}) {
+	l.log(LevelFatal, format, nonNilArgs(args), nil, nil)
+	l.exit()
+}
+
+func (l *instance) Panicf(format string, args ...interface{}) {
+	l.log(LevelPanic, format, nonNilArgs(args), nil, nil)
+	panic(fmt.Sprintf(format, args...))
+}
+
+func (l *instance) Errorf(format string, args ...interface{}) {
+	l.log(LevelError, format, nonNilArgs(args), nil, nil)
+}
+
+func (l *instance) Warnf(format string, args ...interface{}) {
+	l.log(LevelWarn, format, nonNilArgs(args), nil, nil)
+}
+
+func (l *instance) Infof(format string, args ...interface{}) {
+	l.log(LevelInfo, format, nonNilArgs(args), nil, nil)
+}
+
+func (l *instance) Debugf(format string, args ...interface{}) {
+	if l.enabled(LevelDebug) {
+		l.log(LevelDebug, format, nonNilArgs(args), nil, nil)
+	}
+}
+
+func (l *instance) Tracef(format string, args ...interface{}) {
+	if l.enabled(LevelTrace) {
+		l.log(LevelTrace, format, nonNilArgs(args), nil, nil)
 	}
 }
 
@@ -108,16 +304,41 @@ func MustGetValidLevel(level string) string {
 
 // GetValidLevel parses a level string and returns a valid level name if found.
 func GetValidLevel(level string) (string, error) {
-	var allLevels = []string{LvlError, LvlWarn, LvlInfo, LvlDebug, LvlTrace}
-	for _, l := range allLevels {
-		if strings.EqualFold(l, level) {
-			return l, nil
-		}
+	l, err := ParseLevel(level)
+	if err != nil {
+		return "", err
 	}
-	return "", fmt.Errorf("invalid level: %s", level)
+	return l.String(), nil
 }
 
-func (l *instance) log(level string, message string, keysAndValues ...interface{}) {
+// log writes a record. If msgArgs is not nil, message is a format string for msgArgs.
+func (l *instance) log(level Level, message string, msgArgs []interface{}, fields []Field, keysAndValues []interface{}) {
+	if l.sampler != nil && !l.sampler.sample(level, message, l.clock) {
+		return
+	}
+
+	if len(l.hooks) > 0 {
+		if msgArgs != nil {
+			message = fmt.Sprintf(message, noescape_interfaceslice(&msgArgs)...)
+			msgArgs = nil
+		}
+		hookMessage, hookKeysAndValues, keep := l.runHooks(level.String(), noescape_string(&message), noescape_fieldslice(&fields), noescape_interfaceslice(&keysAndValues))
+		if !keep {
+			// Mistakes are reported even if a hook drops the record, e.g. the Recorder of logtest.
+			if l.keyPolicy.Panic {
+				l.checkKeys(noescape_fieldslice(&fields), noescape_interfaceslice(&keysAndValues))
+			}
+			return
+		}
+		message, keysAndValues, fields = hookMessage, hookKeysAndValues, nil
+	}
+
+	fields, keysAndValues = resolveLazyValues(noescape_fieldslice(&fields), noescape_interfaceslice(&keysAndValues))
+
+	if l.keyPolicy.Panic {
+		l.checkKeys(noescape_fieldslice(&fields), noescape_interfaceslice(&keysAndValues))
+	}
+
 	// We must lock here, because we don't know for sure if the current io.writer uses locking
 	l.mutex.Lock()
 	defer l.mutex.Unlock()
@@ -125,39 +346,53 @@ func (l *instance) log(level string, message string, keysAndValues ...interface{
 	sw := MakeStackWriter(l.writer)
 	defer sw.Flush()
 
-	now := FormatLogTime(time.Now())
-
-	sw.Write("{\"ts\": ")
-	sw.WriteJSONString(string(now[:]))
-	sw.Write(", \"level\": ")
-	sw.WriteJSONString(level)
-	sw.Write(", \"message\": ")
-	sw.WriteJSONString(message)
+	s := l.schema
+	if s.time != "" {
+		sw.Write(s.time)
+		l.writeTimestamp(&sw)
+	}
+	sw.Write(s.level)
+	sw.WriteJSONString(s.levelName(level))
+	sw.Write(s.message)
+	if msgArgs != nil {
+		writeFormatted(&sw, noescape_string(&message), noescape_interfaceslice(&msgArgs))
+	} else {
+		sw.WriteJSONString(message)
+	}
+	if l.name != "" && s.logger != "" {
+		sw.Write(s.logger)
+		sw.WriteJSONString(l.name)
+	}
 
-	fn := len(keysAndValues)
-	for i := 0; i+1 < fn; i += 2 {
-		sw.Write(", ")
-		encodeKey(&sw, noescape_interface(&keysAndValues[i]))
-		sw.Write(": ")
-		encodeValue(&sw, noescape_interface(&keysAndValues[i+1]))
+	if l.keyPolicy.Deduplicate {
+		l.writeStaticFields(&sw, noescape_fieldslice(&fields), noescape_interfaceslice(&keysAndValues))
+	} else {
+		sw.Write(l.staticFields)
 	}
 
-	if includeCallerInfo(level) {
+	l.writeFields(&sw, noescape_fieldslice(&fields), noescape_interfaceslice(&keysAndValues))
+
+	if level >= l.callerLevel && s.hasCaller {
 		funcName, fileName, line := retrieveCallInfo()
-		sw.Write(", \"caller_func\": ")
-		sw.WriteJSONString(funcName)
-		sw.Write(", \"caller_file\": \"")
-		sw.WriteEscaped(fileName)
-		sw.Write(":")
-		sw.Write(strconv.Itoa(line))
-		sw.Write("\"")
+		s.writeCaller(&sw, funcName, fileName, line)
+	}
+
+	if level >= l.stackTraceLevel && s.stackTrace != "" {
+		sw.Write(s.stackTrace)
+		sw.WriteJSONString(stackTrace())
 	}
 
 	sw.Write("}\n")
 }
 
-func includeCallerInfo(level string) bool {
-	return level == LvlError || level == LvlWarn
+func (l *instance) writeTimestamp(sw *StackWriter) {
+	if l.coarseClock != nil {
+		sw.Write(l.coarseClock.formattedTimestamp())
+		return
+	}
+
+	var buf [40]byte
+	sw.Write(bytesToString(AppendLogTime(buf[:0], clockNow(l.clock), l.timeFormat, l.timeLocation)))
 }
 
 func retrieveCallInfo() (funcName string, file string, line int) {
@@ -165,5 +400,32 @@ func retrieveCallInfo() (funcName string, file string, line int) {
 	if !ok {
 		return "", "", -1
 	}
-	return runtime.FuncForPC(pc).Name(), file, line
+	funcName = runtime.FuncForPC(pc).Name()
+	if funcName == stdLogWriteFunc {
+		return stdLogCaller()
+	}
+	return funcName, file, line
+}
+
+// stackTrace returns the stack of the goroutine that called the log method, formatted
+// like the frames of a panic.
+func stackTrace() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(4, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var b strings.Builder
+	for {
+		frame, more := frames.Next()
+		b.WriteString(frame.Function)
+		b.WriteString("\n\t")
+		b.WriteString(frame.File)
+		b.WriteString(":")
+		b.WriteString(strconv.Itoa(frame.Line))
+		if !more {
+			break
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
 }
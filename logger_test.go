@@ -3,9 +3,13 @@ package logger
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -120,3 +124,132 @@ func TestLogger_Allocs_Structured(t *testing.T) {
 		t.Errorf("Allocs detected! Want 0 allocs, got %f", allocs)
 	}
 }
+
+func FuzzLogger_log(f *testing.F) {
+	f.Add("msg", "key", "value", 1.5, int64(42), uint8(0), uint16(0), uint8(0))
+	f.Add("", "", "", math.NaN(), int64(-1), uint8(1), uint16(bufSize-60), uint8(1))
+	f.Add("line\nbreak \"quoted\" \\", "key\t\x00", "\x1f\x7f ", math.Inf(-1), int64(math.MinInt64), uint8(4), uint16(bufSize-70), uint8(3))
+	f.Add("\xff\xfe invalid", "\xc3", "\xe2\x82", math.MaxFloat64, int64(math.MaxInt64), uint8(6), uint16(bufSize+5), uint8(16))
+	f.Add(makeString(bufSize), "level", makeString(2*bufSize), -0.0, int64(1e18), uint8(8), uint16(1), uint8(255))
+	f.Fuzz(func(t *testing.T, msg string, key string, value string, number float64, integer int64, kind uint8, pad uint16, chunkSize uint8) {
+		clock := NewFakeClock(time.Date(2022, 2, 1, 13, 1, 2, 0, time.UTC))
+		// The padding shifts the position of the message and fields in the buffer.
+		padding := WithFields(String("pad", makeString(int(pad)%(2*bufSize))))
+
+		out := &bytes.Buffer{}
+		logger := NewWithWriter(LvlInfo, out, WithClock(clock), padding)
+		chunked := &chunkWriter{size: int(chunkSize)*8 + 1}
+		chunkedLogger := NewWithWriter(LvlInfo, chunked, WithClock(clock), padding)
+
+		expectedString := string([]rune(value))
+		for _, test := range []struct {
+			log func(l Logger)
+			// value is the expected value of the last field, nil if it is not checked.
+			value interface{}
+		}{
+			{func(l Logger) { l.Info(msg, key, value) }, expectedString},
+			{func(l Logger) { l.InfoF(msg, String(key, value)) }, expectedString},
+			{func(l Logger) { l.Info(msg, key, number) }, expectedFloat(number)},
+			{func(l Logger) { l.InfoF(msg, Float64(key, number)) }, expectedFloat(number)},
+			{func(l Logger) { l.Info(msg, key, integer) }, json.Number(strconv.FormatInt(integer, 10))},
+			{func(l Logger) { l.InfoF(msg, Time(key, time.Unix(integer, int64(number)))) }, nil},
+			{func(l Logger) { l.Info(msg, key, fuzzValue(kind, value, number, integer)) }, nil},
+		} {
+			out.Reset()
+			chunked.Reset()
+			chunked.errors = 0
+			test.log(logger)
+			test.log(chunkedLogger)
+
+			record := out.String()
+			if !strings.HasSuffix(record, "\n") {
+				t.Fatalf("Record does not end with a line break: %q", record)
+			}
+			assertSingleLine(t, strings.TrimSuffix(record, "\n"))
+			fields, err := decodeRecord(record)
+			if err != nil {
+				t.Fatalf("Record is not a valid JSON object: %v\n%q", err, record)
+			}
+			if fields[2] != [2]interface{}{"message", string([]rune(msg))} {
+				t.Fatalf("Message does not round-trip, Expected %q, Actual %q", msg, fields[2][1])
+			}
+			last := fields[len(fields)-1]
+			if last[0] != string([]rune(key)) {
+				t.Fatalf("Key does not round-trip, Expected %q, Actual %q", key, last[0])
+			}
+			if test.value != nil && last[1] != test.value {
+				t.Fatalf("Value does not round-trip, Expected %#v, Actual %#v", test.value, last[1])
+			}
+			assertWriterOutput(t, chunked, out.Bytes())
+		}
+	})
+}
+
+// expectedFloat returns how a float64 is decoded from a record.
+func expectedFloat(f float64) interface{} {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	}
+	return json.Number(strconv.FormatFloat(f, 'f', 6, 64))
+}
+
+// fuzzValue returns one of the values that are not encoded as string, selected by kind.
+func fuzzValue(kind uint8, value string, number float64, integer int64) interface{} {
+	switch kind % 10 {
+	case 0:
+		return float32(number)
+	case 1:
+		return uint64(integer)
+	case 2:
+		return errors.New(value)
+	case 3:
+		return time.Unix(integer, 0)
+	case 4:
+		return testCustomer{Name: value, Email: value, Age: int(integer), Address: &testAddress{Street: value}}
+	case 5:
+		return testFailingMarshaler{}
+	case 6:
+		return testPanicStringer{}
+	case 7:
+		return []interface{}{number, value, integer, nil}
+	case 8:
+		return map[string]interface{}{value: number, "nested": map[string]float64{value: number}}
+	default:
+		return RawJSON(value)
+	}
+}
+
+// decodeRecord returns the key/value pairs of a record in the order they were written.
+// Unlike decoding into a map, duplicate keys are kept.
+func decodeRecord(record string) ([][2]interface{}, error) {
+	dec := json.NewDecoder(strings.NewReader(record))
+	dec.UseNumber()
+	if token, err := dec.Token(); err != nil || token != json.Delim('{') {
+		return nil, fmt.Errorf("record does not start with an object: %v %v", token, err)
+	}
+
+	var fields [][2]interface{}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		fields = append(fields, [2]interface{}{key, value})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("data after the record: %v", err)
+	}
+	return fields, nil
+}
@@ -0,0 +1,15 @@
+// Command loggervet checks calls of the logger methods Log, Fatal, Panic, Error, Warn, Info, Debug
+// and Trace.
+//
+//	go install github.com/fond-of-vertigo/logger/loggervet/cmd/loggervet@latest
+//	go vet -vettool=$(which loggervet) ./...
+package main
+
+import (
+	"github.com/fond-of-vertigo/logger/loggervet"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(loggervet.Analyzer)
+}
@@ -0,0 +1,240 @@
+// Package loggervet defines an analyzer that checks calls of the logger methods
+// Log, Fatal, Panic, Error, Warn, Info, Debug and Trace for mistakes in the message and the key/value pairs.
+package loggervet
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"regexp"
+	"strconv"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const loggerPkgPath = "github.com/fond-of-vertigo/logger"
+
+const doc = `check calls of logger.Logger methods
+
+The loggervet analyzer reports calls of Log, Fatal, Panic, Error, Warn, Info, Debug and Trace with
+  - an odd number of key/value arguments,
+  - keys that are not strings or not constant,
+  - duplicate keys,
+  - keys that shadow the record fields ts, level, message, logger,
+    caller_func, caller_file and stacktrace,
+  - printf verbs like %s or %d in the message.`
+
+// Analyzer checks calls of logger methods.
+var Analyzer = &analysis.Analyzer{
+	Name:     "loggervet",
+	Doc:      doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+var nonConstKeys bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&nonConstKeys, "nonconst", true, "report keys that are not constant strings")
+}
+
+// ReservedKeyPrefix is suggested as prefix for keys that shadow record fields.
+const ReservedKeyPrefix = "fields."
+
+// logMethods maps the names of the checked methods to the index of the message argument.
+var logMethods = map[string]int{
+	"Fatal": 0,
+	"Panic": 0,
+	"Error": 0,
+	"Warn":  0,
+	"Info":  0,
+	"Debug": 0,
+	"Trace": 0,
+	"Log":   1,
+}
+
+var reservedKeys = map[string]bool{
+	"ts":          true,
+	"level":       true,
+	"message":     true,
+	"logger":      true,
+	"caller_func": true,
+	"caller_file": true,
+	"stacktrace":  true,
+}
+
+var printfVerb = regexp.MustCompile(`%[-+# 0]*(\d+|\*)?(\.(\d+|\*))?[vTtbcdoOqxXUeEfFgGsp]`)
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	inspect.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		if !isLogMethodCall(pass, call) {
+			return
+		}
+		method := call.Fun.(*ast.SelectorExpr).Sel
+		msgIndex := logMethods[method.Name]
+		if len(call.Args) <= msgIndex {
+			return
+		}
+
+		if method.Name != "Log" {
+			// There is no printf variant of Log.
+			checkMessage(pass, method, call.Args[msgIndex])
+		}
+		if call.Ellipsis.IsValid() {
+			// Called with a slice, the pairs are not known.
+			return
+		}
+		checkKeysAndValues(pass, call.Args[msgIndex+1:])
+	})
+	return nil, nil
+}
+
+// isLogMethodCall returns true if call is a call of one of the log methods declared in
+// the logger package, either on the Logger interface or on a logger implementation.
+func isLogMethodCall(pass *analysis.Pass, call *ast.CallExpr) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	if _, ok := logMethods[sel.Sel.Name]; !ok {
+		return false
+	}
+	selection, ok := pass.TypesInfo.Selections[sel]
+	if !ok || selection.Kind() != types.MethodVal {
+		return false
+	}
+	fn, ok := selection.Obj().(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != loggerPkgPath {
+		return false
+	}
+	sig, ok := fn.Type().(*types.Signature)
+	return ok && sig.Variadic()
+}
+
+func checkMessage(pass *analysis.Pass, method *ast.Ident, msg ast.Expr) {
+	s, ok := constantString(pass, msg)
+	if !ok {
+		return
+	}
+	for _, loc := range printfVerb.FindAllStringIndex(s, -1) {
+		if loc[0] > 0 && s[loc[0]-1] == '%' {
+			// escaped %%
+			continue
+		}
+		pass.Report(analysis.Diagnostic{
+			Pos:     msg.Pos(),
+			End:     msg.End(),
+			Message: "log message contains printf verb " + s[loc[0]:loc[1]] + ", use " + method.Name + "f or key/value pairs instead",
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message: "Use " + method.Name + "f",
+				TextEdits: []analysis.TextEdit{{
+					Pos:     method.Pos(),
+					End:     method.End(),
+					NewText: []byte(method.Name + "f"),
+				}},
+			}},
+		})
+		return
+	}
+}
+
+func checkKeysAndValues(pass *analysis.Pass, args []ast.Expr) {
+	if len(args)%2 == 1 {
+		last := args[len(args)-1]
+		pass.ReportRangef(last, "odd number of key/value arguments, %s has no value", types.ExprString(last))
+	}
+
+	seen := map[string]int{}
+	for i := 0; i < len(args); i += 2 {
+		key := args[i]
+		k, ok := constantString(pass, key)
+		if !ok {
+			checkNonConstantKey(pass, key)
+			continue
+		}
+
+		if reservedKeys[k] {
+			pass.Report(analysis.Diagnostic{
+				Pos:     key.Pos(),
+				End:     key.End(),
+				Message: "key " + strconv.Quote(k) + " shadows a record field",
+				SuggestedFixes: []analysis.SuggestedFix{{
+					Message: "Prefix key with " + strconv.Quote(ReservedKeyPrefix),
+					TextEdits: []analysis.TextEdit{{
+						Pos:     key.Pos(),
+						End:     key.End(),
+						NewText: []byte(strconv.Quote(ReservedKeyPrefix + k)),
+					}},
+				}},
+			})
+		}
+
+		if first, ok := seen[k]; ok {
+			// Removes the first pair up to the start of the next argument.
+			end := args[first+1].End()
+			if first+2 < len(args) {
+				end = args[first+2].Pos()
+			}
+			pass.Report(analysis.Diagnostic{
+				Pos:     key.Pos(),
+				End:     key.End(),
+				Message: "duplicate key " + strconv.Quote(k),
+				SuggestedFixes: []analysis.SuggestedFix{{
+					Message: "Remove first pair with key " + strconv.Quote(k),
+					TextEdits: []analysis.TextEdit{{
+						Pos: args[first].Pos(),
+						End: end,
+					}},
+				}},
+			})
+			continue
+		}
+		seen[k] = i
+	}
+}
+
+func checkNonConstantKey(pass *analysis.Pass, key ast.Expr) {
+	t := pass.TypesInfo.TypeOf(key)
+	if t == nil {
+		return
+	}
+	if basic, ok := t.Underlying().(*types.Basic); ok && basic.Info()&types.IsString != 0 {
+		if nonConstKeys {
+			pass.ReportRangef(key, "key %s is not a constant string", types.ExprString(key))
+		}
+		return
+	}
+	if isStringer(t) {
+		if nonConstKeys {
+			pass.ReportRangef(key, "key %s is not a constant string", types.ExprString(key))
+		}
+		return
+	}
+	pass.ReportRangef(key, "key %s of type %s is not a string", types.ExprString(key), t)
+}
+
+func isStringer(t types.Type) bool {
+	obj, _, _ := types.LookupFieldOrMethod(t, true, nil, "String")
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.Params().Len() != 0 || sig.Results().Len() != 1 {
+		return false
+	}
+	basic, ok := sig.Results().At(0).Type().(*types.Basic)
+	return ok && basic.Kind() == types.String
+}
+
+func constantString(pass *analysis.Pass, expr ast.Expr) (string, bool) {
+	tv, ok := pass.TypesInfo.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
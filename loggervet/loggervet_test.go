@@ -0,0 +1,12 @@
+package loggervet_test
+
+import (
+	"testing"
+
+	"github.com/fond-of-vertigo/logger/loggervet"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), loggervet.Analyzer, "a")
+}
@@ -0,0 +1,34 @@
+package a
+
+import (
+	"errors"
+
+	"github.com/fond-of-vertigo/logger"
+)
+
+type id int
+
+func (id) String() string { return "id" }
+
+type other struct{}
+
+func (other) Info(msg string, keysAndValues ...interface{}) {}
+
+func calls(log logger.Logger, key string, kv []interface{}) {
+	log.Info("valid", "a", 1, "b", "c")
+	log.Info("escaped 100%% done")
+	log.Info("Test %d: %s", 1, "Lorem ipsum") // want `log message contains printf verb %d, use Infof` `key 1 of type int is not a string`
+	log.Error("failed", errors.New("x"))      // want `key errors.New\("x"\) of type error is not a string` `odd number of key/value arguments`
+	log.Warn("odd", "a", 1, "b")              // want `odd number of key/value arguments, "b" has no value`
+	log.Debug("dynamic", key, 1)              // want `key key is not a constant string`
+	log.Debug("stringer", id(1), 1)           // want `key id\(1\) is not a constant string`
+	log.Trace("int key", 1, 2)                // want `key 1 of type int is not a string`
+	log.Info("dup", "a", 1, "b", 2, "a", 3)   // want `duplicate key "a"`
+	log.Info("reserved", "message", "x")      // want `key "message" shadows a record field`
+	log.Fatal("stack", "stacktrace", "x")     // want `key "stacktrace" shadows a record field`
+	log.Log(2, "level %d", "a", 1, "a", 2)    // want `duplicate key "a"`
+	log.Info("slice", kv...)
+
+	logger.NewWithWriter("INFO").Info("dup", "x", 1, "x", 2) // want `duplicate key "x"`
+	other{}.Info("not a logger", 1)
+}
@@ -0,0 +1,43 @@
+package logger
+
+type Level int
+
+type Logger interface {
+	Log(level Level, msg string, keysAndValues ...interface{})
+	Fatal(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Debug(msg string, keysAndValues ...interface{})
+	Trace(msg string, keysAndValues ...interface{})
+
+	Errorf(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Debugf(format string, args ...interface{})
+	Tracef(format string, args ...interface{})
+}
+
+type instance struct{}
+
+func (l *instance) Log(level Level, msg string, keysAndValues ...interface{}) {}
+func (l *instance) Fatal(msg string, keysAndValues ...interface{})            {}
+func (l *instance) Error(msg string, keysAndValues ...interface{})            {}
+func (l *instance) Warn(msg string, keysAndValues ...interface{})             {}
+func (l *instance) Info(msg string, keysAndValues ...interface{})             {}
+func (l *instance) Debug(msg string, keysAndValues ...interface{})            {}
+func (l *instance) Trace(msg string, keysAndValues ...interface{})            {}
+
+func (l *instance) Errorf(format string, args ...interface{}) {}
+func (l *instance) Warnf(format string, args ...interface{})  {}
+func (l *instance) Infof(format string, args ...interface{})  {}
+func (l *instance) Debugf(format string, args ...interface{}) {}
+func (l *instance) Tracef(format string, args ...interface{}) {}
+
+func New(level string) Logger {
+	return &instance{}
+}
+
+func NewWithWriter(level string) *instance {
+	return &instance{}
+}
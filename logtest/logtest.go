@@ -0,0 +1,315 @@
+// Package logtest helps testing code that logs. A Recorder captures records as entries that
+// can be filtered and asserted without parsing JSON:
+//
+//	rec := logtest.NewRecorder(logger.LvlDebug)
+//	service := NewService(rec)
+//	service.Pay(order)
+//	rec.AssertLogged(t, logger.LvlError, "Payment failed", "order", order.ID)
+package logtest
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fond-of-vertigo/logger"
+)
+
+// Field is a key/value pair of an entry.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Entry is a captured record.
+type Entry struct {
+	Level   string
+	Logger  string
+	Message string
+	// Fields are the key/value pairs and typed fields, in the order they were logged.
+	Fields []Field
+	// Caller is the function that called the log method.
+	Caller runtime.Frame
+}
+
+// Get returns the value of the first field with the given key.
+func (e Entry) Get(key string) (value interface{}, ok bool) {
+	for _, f := range e.Fields {
+		if f.Key == key {
+			return f.Value, true
+		}
+	}
+	return nil, false
+}
+
+// String formats the entry for test failures.
+func (e Entry) String() string {
+	var b strings.Builder
+	b.WriteString(e.Level)
+	b.WriteString(" ")
+	b.WriteString(strconv.Quote(e.Message))
+	for _, f := range e.Fields {
+		fmt.Fprintf(&b, " %s=%#v", f.Key, f.Value)
+	}
+	if e.Logger != "" {
+		b.WriteString(" logger=" + e.Logger)
+	}
+	if e.Caller.File != "" {
+		fmt.Fprintf(&b, " (%s:%d)", e.Caller.File, e.Caller.Line)
+	}
+	return b.String()
+}
+
+// Entries is a list of entries that can be filtered.
+type Entries []Entry
+
+// Level returns the entries of the given level.
+func (es Entries) Level(level string) Entries {
+	return es.filter(func(e Entry) bool { return e.Level == level })
+}
+
+// Message returns the entries with the given message.
+func (es Entries) Message(msg string) Entries {
+	return es.filter(func(e Entry) bool { return e.Message == msg })
+}
+
+// MessageContains returns the entries whose message contains s.
+func (es Entries) MessageContains(s string) Entries {
+	return es.filter(func(e Entry) bool { return strings.Contains(e.Message, s) })
+}
+
+// Field returns the entries that have a field with the given key and value.
+func (es Entries) Field(key string, value interface{}) Entries {
+	return es.filter(func(e Entry) bool {
+		v, ok := e.Get(key)
+		return ok && valuesEqual(v, value)
+	})
+}
+
+func (es Entries) filter(keep func(e Entry) bool) Entries {
+	var filtered Entries
+	for _, e := range es {
+		if keep(e) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+func (es Entries) String() string {
+	if len(es) == 0 {
+		return "no entries"
+	}
+	lines := make([]string, len(es))
+	for i, e := range es {
+		lines[i] = e.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Recorder is a logger.Logger that captures records instead of writing them. It is safe
+// for concurrent use, loggers created by Named record into the same Recorder.
+//
+// Fatal and Fatalf of the Recorder record a FATAL entry and mark the recorder as exited
+// instead of exiting. Panic and Panicf record and panic like any logger.
+type Recorder struct {
+	logger.Logger
+
+	mutex   sync.Mutex
+	entries Entries
+	exited  bool
+}
+
+// NewRecorder creates a Recorder with the given level. Options are applied to the
+// underlying logger, e.g. hooks or a key policy.
+func NewRecorder(level string, opts ...logger.Option) *Recorder {
+	r := &Recorder{}
+	recorderOpts := []logger.Option{
+		logger.WithExitFunc(func(int) { r.setExited() }),
+	}
+	opts = append(append(recorderOpts, opts...), logger.WithHooks(r.record))
+	r.Logger = logger.NewWithWriter(level, io.Discard, opts...)
+	return r
+}
+
+// Fatal records a FATAL entry and marks the recorder as exited.
+func (r *Recorder) Fatal(msg string, keysAndValues ...interface{}) {
+	r.Logger.Log(logger.LevelFatal, msg, keysAndValues...)
+	r.setExited()
+}
+
+// Fatalf records a FATAL entry and marks the recorder as exited.
+func (r *Recorder) Fatalf(format string, args ...interface{}) {
+	r.Logger.Log(logger.LevelFatal, fmt.Sprintf(format, args...))
+	r.setExited()
+}
+
+// Exited returns true if Fatal was called.
+func (r *Recorder) Exited() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.exited
+}
+
+func (r *Recorder) setExited() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.exited = true
+}
+
+// Entries returns a copy of all captured entries.
+func (r *Recorder) Entries() Entries {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append(Entries(nil), r.entries...)
+}
+
+// Reset removes all captured entries.
+func (r *Recorder) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.entries = nil
+	r.exited = false
+}
+
+// AssertLogged fails the test if no entry has the given level, message and key/value pairs.
+// The entry may have other fields. Numbers are compared by value, so 42 matches int64(42).
+func (r *Recorder) AssertLogged(t testing.TB, level string, msg string, keysAndValues ...interface{}) bool {
+	t.Helper()
+	if len(r.matching(level, msg, keysAndValues)) == 0 {
+		t.Errorf("No entry %s %q %v was logged, entries:\n%s", level, msg, keysAndValues, r.Entries())
+		return false
+	}
+	return true
+}
+
+// AssertNotLogged fails the test if an entry has the given level, message and key/value pairs.
+func (r *Recorder) AssertNotLogged(t testing.TB, level string, msg string, keysAndValues ...interface{}) bool {
+	t.Helper()
+	if matching := r.matching(level, msg, keysAndValues); len(matching) > 0 {
+		t.Errorf("Unexpected entry was logged:\n%s", matching)
+		return false
+	}
+	return true
+}
+
+func (r *Recorder) matching(level string, msg string, keysAndValues []interface{}) Entries {
+	entries := r.Entries().Level(level).Message(msg)
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		entries = entries.Field(fmt.Sprint(keysAndValues[i]), keysAndValues[i+1])
+	}
+	return entries
+}
+
+// record is the hook that captures the entries. It drops the records, so nothing is encoded.
+func (r *Recorder) record(e *logger.Entry) bool {
+	entry := Entry{
+		Level:   e.Level,
+		Logger:  e.Logger,
+		Message: e.Message,
+		Caller:  caller(),
+	}
+	for i := 0; i+1 < len(e.KeysAndValues); i += 2 {
+		entry.Fields = append(entry.Fields, Field{
+			Key:   fmt.Sprint(e.KeysAndValues[i]),
+			Value: plainValue(e.KeysAndValues[i+1]),
+		})
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.entries = append(r.entries, entry)
+	return false
+}
+
+// plainValue returns the value of typed fields and lazy values.
+func plainValue(v interface{}) interface{} {
+	if f, ok := v.(logger.Field); ok {
+		v = f.Value()
+	}
+	if lazy, ok := v.(logger.LazyValue); ok {
+		v = lazy.LogValue()
+	}
+	return v
+}
+
+const (
+	loggerPrefix   = "github.com/fond-of-vertigo/logger."
+	recorderPrefix = "github.com/fond-of-vertigo/logger/logtest.(*Recorder)."
+)
+
+// caller returns the first frame outside of the logger package, the Recorder and the
+// standard library log package, see logger.NewStdLogger.
+func caller() runtime.Frame {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, loggerPrefix) && !strings.HasPrefix(frame.Function, recorderPrefix) &&
+			!strings.HasPrefix(frame.Function, "log.") {
+			return frame
+		}
+		if !more {
+			return runtime.Frame{}
+		}
+	}
+}
+
+// valuesEqual compares values like reflect.DeepEqual, but numbers by value and errors by
+// their message if expected is a string.
+func valuesEqual(actual, expected interface{}) bool {
+	if reflect.DeepEqual(actual, expected) {
+		return true
+	}
+	if err, ok := actual.(error); ok {
+		if s, ok := expected.(string); ok {
+			return err.Error() == s
+		}
+	}
+
+	a, aOK := toFloat(actual)
+	e, eOK := toFloat(expected)
+	return aOK && eOK && a == e
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// NewTestWriter returns a writer that passes each line to t.Log, so the output of a logger
+// is shown with the test that wrote it, and only if the test fails or runs verbose.
+func NewTestWriter(t testing.TB) io.Writer {
+	return testWriter{t: t}
+}
+
+// NewTestLogger creates a logger that writes to t.Log.
+func NewTestLogger(t testing.TB, level string, opts ...logger.Option) logger.Logger {
+	return logger.NewWithWriter(level, NewTestWriter(t), opts...)
+}
+
+type testWriter struct {
+	t testing.TB
+}
+
+func (w testWriter) Write(p []byte) (int, error) {
+	w.t.Helper()
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		w.t.Log(line)
+	}
+	return len(p), nil
+}
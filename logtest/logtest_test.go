@@ -0,0 +1,150 @@
+package logtest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fond-of-vertigo/logger"
+)
+
+func TestRecorder(t *testing.T) {
+	rec := NewRecorder(logger.LvlDebug)
+	rec.Info("Started", "port", 8080)
+	rec.ErrorF("Payment failed", logger.Int64("order", 42), logger.Err(errors.New("declined")))
+	rec.Named("db").Debug("Query", "rows", logger.Lazy(func() interface{} { return 3 }))
+	rec.Trace("Disabled")
+
+	entries := rec.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, Actual:\n%s", entries)
+	}
+
+	first := entries[0]
+	if first.Level != logger.LvlInfo || first.Message != "Started" || len(first.Fields) != 1 || first.Fields[0] != (Field{"port", 8080}) {
+		t.Errorf("Entry is incorrect: %s", first)
+	}
+	if !strings.HasSuffix(first.Caller.Function, "logtest.TestRecorder") || !strings.HasSuffix(first.Caller.File, "logtest_test.go") {
+		t.Errorf("Caller is incorrect: %+v", first.Caller)
+	}
+	if entries[2].Logger != "db" {
+		t.Errorf("Logger is incorrect: %s", entries[2])
+	}
+
+	rec.AssertLogged(t, logger.LvlError, "Payment failed", "order", 42, "error", "declined")
+	rec.AssertLogged(t, logger.LvlDebug, "Query", "rows", 3)
+	rec.AssertNotLogged(t, logger.LvlTrace, "Disabled")
+
+	if n := len(entries.Level(logger.LvlError)); n != 1 {
+		t.Errorf("Expected 1 error entry, Actual %d", n)
+	}
+	if n := len(entries.Field("port", int64(8080)).Message("Started")); n != 1 {
+		t.Errorf("Expected 1 entry with port, Actual %d", n)
+	}
+	if n := len(entries.MessageContains("a")); n != 2 {
+		t.Errorf("Expected 2 entries containing a, Actual %d", n)
+	}
+
+	rec.Reset()
+	if len(rec.Entries()) != 0 {
+		t.Errorf("Entries were not removed")
+	}
+}
+
+func TestRecorder_Assertions_Fail(t *testing.T) {
+	rec := NewRecorder(logger.LvlInfo)
+	rec.Info("msg", "a", 1)
+
+	for name, assert := range map[string]func(tb testing.TB) bool{
+		"wrong value": func(tb testing.TB) bool { return rec.AssertLogged(tb, logger.LvlInfo, "msg", "a", 2) },
+		"wrong level": func(tb testing.TB) bool { return rec.AssertLogged(tb, logger.LvlWarn, "msg") },
+		"missing key": func(tb testing.TB) bool { return rec.AssertLogged(tb, logger.LvlInfo, "msg", "b", 1) },
+		"logged":      func(tb testing.TB) bool { return rec.AssertNotLogged(tb, logger.LvlInfo, "msg", "a", 1) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			tb := &recordingTB{TB: t}
+			if assert(tb) || !tb.failed {
+				t.Errorf("Assertion did not fail")
+			}
+		})
+	}
+}
+
+func TestRecorder_Fatal(t *testing.T) {
+	rec := NewRecorder(logger.LvlInfo)
+	rec.Fatal("Cannot recover", "a", 1)
+	if !rec.Exited() {
+		t.Errorf("Fatal was not recorded as exit")
+	}
+	rec.AssertLogged(t, logger.LvlFatal, "Cannot recover", "a", 1)
+
+	rec.Reset()
+	rec.Named("child").Fatalf("Cannot %s", "recover")
+	if !rec.Exited() {
+		t.Errorf("Fatal of named logger was not recorded as exit")
+	}
+	rec.AssertLogged(t, logger.LvlFatal, "Cannot recover")
+}
+
+func TestRecorder_KeyPolicy(t *testing.T) {
+	rec := NewRecorder(logger.LvlInfo, logger.WithKeyPolicy(logger.KeyPolicy{Panic: true}))
+	rec.Info("valid", "a", 1)
+
+	for name, log := range map[string]func(){
+		"odd count":     func() { rec.Info("msg", "a") },
+		"duplicate key": func() { rec.Info("msg", "a", 1, "a", 2) },
+		"typed fields":  func() { rec.InfoF("msg", logger.Int("a", 1), logger.Int("a", 2)) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("Key policy did not panic")
+				}
+			}()
+			log()
+		})
+	}
+	rec.AssertLogged(t, logger.LvlInfo, "valid", "a", 1)
+}
+
+func TestRecorder_StdLogger(t *testing.T) {
+	rec := NewRecorder(logger.LvlInfo)
+	logger.NewStdLogger(rec, logger.LevelError).Print("http: panic serving")
+
+	entries := rec.Entries()
+	if len(entries) != 1 || entries[0].Level != logger.LvlError || entries[0].Message != "http: panic serving" {
+		t.Fatalf("Entries are incorrect:\n%s", entries)
+	}
+	if !strings.HasSuffix(entries[0].Caller.Function, "logtest.TestRecorder_StdLogger") {
+		t.Errorf("Caller is incorrect: %+v", entries[0].Caller)
+	}
+}
+
+func TestTestWriter(t *testing.T) {
+	tb := &recordingTB{TB: t}
+	NewTestLogger(tb, logger.LvlInfo, logger.WithTimeFormat(logger.TimeNone)).Info("msg", "a", 1)
+
+	expected := []string{`{"level": "INFO", "message": "msg", "a": 1}`}
+	if len(tb.logs) != 1 || tb.logs[0] != expected[0] {
+		t.Errorf("Logs are incorrect, Expected %q, Actual %q", expected, tb.logs)
+	}
+}
+
+// recordingTB records failures and logs instead of passing them to the test.
+type recordingTB struct {
+	testing.TB
+	failed bool
+	logs   []string
+}
+
+func (tb *recordingTB) Helper() {}
+
+func (tb *recordingTB) Errorf(string, ...interface{}) {
+	tb.failed = true
+}
+
+func (tb *recordingTB) Log(args ...interface{}) {
+	for _, arg := range args {
+		tb.logs = append(tb.logs, arg.(string))
+	}
+}
@@ -0,0 +1,35 @@
+package logger
+
+import "io"
+
+// MultiWriter writes every record to all writers, like io.MultiWriter. Unlike io.MultiWriter,
+// a failing writer does not stop the others, the first error is returned. Fatal flushes and
+// closes the writers behind it, which it cannot do for an io.MultiWriter.
+func MultiWriter(writers ...io.Writer) io.Writer {
+	return &multiWriter{writers: append([]io.Writer(nil), writers...)}
+}
+
+type multiWriter struct {
+	writers []io.Writer
+}
+
+func (w *multiWriter) Write(p []byte) (int, error) {
+	var firstErr error
+	for _, writer := range w.writers {
+		n, err := writer.Write(p)
+		if err == nil && n < len(p) {
+			err = io.ErrShortWrite
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return 0, firstErr
+	}
+	return len(p), nil
+}
+
+func (w *multiWriter) sinks() []io.Writer {
+	return w.writers
+}
@@ -0,0 +1,189 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// LevelTable holds level overrides for named loggers. A name matches its own entry and the
+// entries of its parents, the longest match wins: with "payment=DEBUG,payment.client=TRACE"
+// the logger "payment.client.http" uses TRACE and "payment.server" uses DEBUG. The entry "*"
+// matches every logger. Loggers without matching entry keep the level they were created with.
+//
+// The table can be changed at any time. Loggers cache their level and only resolve it
+// again after the table was changed. As for all loggers, INFO and above are always enabled.
+//
+// LevelTable implements flag.Value.
+type LevelTable struct {
+	mutex      sync.RWMutex
+	levels     map[string]Level
+	generation uint32
+}
+
+// NewLevelTable returns a table with the given overrides, see Set.
+func NewLevelTable(spec string) (*LevelTable, error) {
+	t := &LevelTable{}
+	if err := t.Set(spec); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// WithLevelTable makes the logger and all loggers created by Named use the overrides of t.
+func WithLevelTable(t *LevelTable) Option {
+	return func(l *instance) {
+		l.levels = t
+		l.state = 0
+	}
+}
+
+// Set replaces all overrides by the comma separated name=LEVEL pairs of spec, e.g.
+// "payment=DEBUG,payment.client=TRACE,*=INFO". The table is not changed if spec is invalid.
+func (t *LevelTable) Set(spec string) error {
+	levels := map[string]Level{}
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, levelName, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return fmt.Errorf("invalid level override: %q, expected name=LEVEL", pair)
+		}
+		level, err := ParseLevel(strings.TrimSpace(levelName))
+		if err != nil {
+			return fmt.Errorf("invalid level override %q: %w", pair, err)
+		}
+		levels[name] = level
+	}
+
+	t.replace(levels)
+	return nil
+}
+
+// replace replaces all overrides.
+func (t *LevelTable) replace(levels map[string]Level) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.levels = levels
+	atomic.AddUint32(&t.generation, 1)
+}
+
+// SetLevel overrides the level of the named logger and its children.
+func (t *LevelTable) SetLevel(name string, level Level) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.levels == nil {
+		t.levels = map[string]Level{}
+	}
+	t.levels[name] = level
+	atomic.AddUint32(&t.generation, 1)
+}
+
+// Remove deletes the override of the named logger.
+func (t *LevelTable) Remove(name string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.levels, name)
+	atomic.AddUint32(&t.generation, 1)
+}
+
+// String returns the overrides in the format of Set, sorted by name.
+func (t *LevelTable) String() string {
+	if t == nil {
+		return ""
+	}
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	pairs := make([]string, 0, len(t.levels))
+	for name, level := range t.levels {
+		pairs = append(pairs, name+"="+level.String())
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// lookup returns the override for name and the generation of the table.
+func (t *LevelTable) lookup(name string) (level Level, ok bool, generation uint32) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	generation = atomic.LoadUint32(&t.generation)
+	for {
+		if level, ok := t.levels[name]; ok {
+			return level, true, generation
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	level, ok = t.levels["*"]
+	return level, ok, generation
+}
+
+// Named returns a logger for a component. Its records have a "logger" field with the name.
+// Names of nested loggers are joined by dots, log.Named("payment").Named("client") is named
+// "payment.client". The level is taken from the LevelTable of the logger, if there is an
+// override for the name.
+func (l *instance) Named(name string) Logger {
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+
+	return &instance{
+		writer:           l.writer,
+		level:            l.level,
+		name:             name,
+		levels:           l.levels,
+		sampler:          l.sampler,
+		fields:           l.fields,
+		staticFields:     l.staticFields,
+		staticFieldParts: l.staticFieldParts,
+		hooks:            l.hooks,
+		encoder:          l.encoder,
+		keyPolicy:        l.keyPolicy,
+		schema:           l.schema,
+		format:           l.format,
+		timeFormat:       l.timeFormat,
+		timeLocation:     l.timeLocation,
+		clock:            l.clock,
+		coarseClock:      l.coarseClock,
+		callerLevel:      l.callerLevel,
+		stackTraceLevel:  l.stackTraceLevel,
+		exitFunc:         l.exitFunc,
+		mutex:            l.mutex,
+	}
+}
+
+// enabled returns true if records of the given level are written. Levels INFO and above
+// are always enabled.
+func (l *instance) enabled(level Level) bool {
+	return level >= LevelInfo || level >= l.effectiveLevel()
+}
+
+// effectiveLevel returns the level of the logger and resolves it again if the level table
+// was changed.
+func (l *instance) effectiveLevel() Level {
+	if l.levels == nil {
+		return l.level
+	}
+	state := atomic.LoadUint64(&l.state)
+	if state != 0 && uint32(state>>32) == atomic.LoadUint32(&l.levels.generation) {
+		return Level(int32(uint32(state)))
+	}
+
+	level, ok, generation := l.levels.lookup(l.name)
+	if !ok {
+		level = l.level
+	}
+	// The generation starts at 1 after the first change, state 0 means not resolved.
+	atomic.StoreUint64(&l.state, uint64(generation)<<32|uint64(uint32(int32(level))))
+	return level
+}
@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLevelTable_Set(t *testing.T) {
+	levels, err := NewLevelTable(" payment=debug, payment.client=TRACE ,*=INFO,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if levels.String() != "*=INFO,payment.client=TRACE,payment=DEBUG" {
+		t.Errorf("String is incorrect: %s", levels.String())
+	}
+
+	for _, spec := range []string{"payment", "=DEBUG", "payment=LOUD"} {
+		if err := levels.Set(spec); err == nil {
+			t.Errorf("Set(%q) should fail", spec)
+		}
+	}
+	if levels.String() != "*=INFO,payment.client=TRACE,payment=DEBUG" {
+		t.Errorf("Invalid spec changed the table: %s", levels.String())
+	}
+}
+
+func TestLogger_Named_Levels(t *testing.T) {
+	levels, err := NewLevelTable("payment=DEBUG,payment.client=TRACE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := NewWithWriter(LvlInfo, &bytes.Buffer{}, WithLevelTable(levels))
+
+	tests := map[string]string{
+		"payment":             LvlDebug,
+		"payment.server":      LvlDebug,
+		"payment.client":      LvlTrace,
+		"payment.client.http": LvlTrace,
+		"paymentx":            LvlInfo,
+		"shipping":            LvlInfo,
+	}
+	for name, expected := range tests {
+		if level := root.Named(name).GetLevel(); level != expected {
+			t.Errorf("Level of %s is incorrect, Expected %s, Actual %s", name, expected, level)
+		}
+	}
+
+	if level := root.Named("payment").Named("client").GetLevel(); level != LvlTrace {
+		t.Errorf("Level of nested logger is incorrect, Expected %s, Actual %s", LvlTrace, level)
+	}
+	if root.GetLevel() != LvlInfo {
+		t.Errorf("Level of root logger is incorrect, Expected %s, Actual %s", LvlInfo, root.GetLevel())
+	}
+}
+
+func TestLogger_Named_RuntimeChange(t *testing.T) {
+	levels, err := NewLevelTable("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+	logger := NewWithWriter(LvlInfo, out, WithLevelTable(levels)).Named("payment")
+
+	logger.Debug("hidden")
+	levels.SetLevel("payment", LevelDebug)
+	logger.Debug("visible")
+	levels.Set("*=TRACE")
+	logger.Trace("visible")
+	levels.Remove("*")
+	logger.Debug("hidden")
+
+	expected := `"level": "DEBUG", "message": "visible", "logger": "payment"}
+` + `"level": "TRACE", "message": "visible", "logger": "payment"}
+`
+	var actual strings.Builder
+	for _, line := range strings.SplitAfter(out.String(), "\n") {
+		if i := strings.Index(line, `"level"`); i >= 0 {
+			actual.WriteString(line[i:])
+		}
+	}
+	if actual.String() != expected {
+		t.Errorf("Output is incorrect, Expected\n%s\nActual\n%s", expected, actual.String())
+	}
+}
+
+func TestLogger_Named_Concurrent(t *testing.T) {
+	levels, err := NewLevelTable("*=INFO")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+	logger := NewWithWriter(LvlInfo, out, WithLevelTable(levels)).Named("payment")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logger.Debug("msg", "j", j)
+				logger.Info("msg", "j", j)
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		levels.SetLevel("payment", Level(i%2)*LevelDebug)
+	}
+	wg.Wait()
+
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if !strings.HasSuffix(line, `"logger": "payment", "j": `+line[strings.LastIndex(line, " ")+1:]) {
+			t.Fatalf("Record is incorrect: %s", line)
+		}
+	}
+}
+
+func TestLogger_Named_Hooks(t *testing.T) {
+	var names []string
+	logger := NewWithWriter(LvlInfo, &bytes.Buffer{}, WithHooks(func(e *Entry) bool {
+		names = append(names, e.Logger)
+		return true
+	}))
+
+	logger.Info("root")
+	logger.Named("a").Named("b").Info("child")
+
+	if strings.Join(names, ",") != ",a.b" {
+		t.Errorf("Logger names are incorrect: %q", names)
+	}
+}
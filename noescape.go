@@ -34,6 +34,23 @@ func noescape_interface(val *interface{}) interface{} {
 	return *(*interface{})(noescape(unsafe.Pointer(val)))
 }
 
+func noescape_interfaceslice(val *[]interface{}) []interface{} {
+	return *(*[]interface{})(noescape(unsafe.Pointer(val)))
+}
+
+func noescape_fieldslice(val *[]Field) []Field {
+	return *(*[]Field)(noescape(unsafe.Pointer(val)))
+}
+
+func noescape_fieldptr(val *Field) *Field {
+	return (*Field)(noescape(unsafe.Pointer(val)))
+}
+
+// bytesToString converts without copying, b must not be modified while the string is used.
+func bytesToString(b []byte) string {
+	return *(*string)(unsafe.Pointer(&b))
+}
+
 // noescape hides a pointer from escape analysis. It is the identity function
 // but escape analysis doesn't think the output depends on the input.
 // noescape is inlined and currently compiles down to zero instructions.
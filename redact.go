@@ -0,0 +1,276 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// Redacted is written instead of sensitive values.
+const Redacted = "[REDACTED]"
+
+// ValuePattern matches sensitive content inside string values.
+type ValuePattern struct {
+	Name   string
+	Regexp *regexp.Regexp
+	// Validate is optional and can reject false positives of the regular expression.
+	Validate func(match string) bool
+}
+
+var (
+	// PatternCreditCard matches credit card numbers with a valid Luhn check digit.
+	PatternCreditCard = ValuePattern{
+		Name:     "credit_card",
+		Regexp:   regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		Validate: luhnValid,
+	}
+	// PatternJWT matches JSON Web Tokens.
+	PatternJWT = ValuePattern{
+		Name:   "jwt",
+		Regexp: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`),
+	}
+	// PatternEmail matches email addresses.
+	PatternEmail = ValuePattern{
+		Name:   "email",
+		Regexp: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	}
+	// PatternIBAN matches IBANs with a valid checksum.
+	PatternIBAN = ValuePattern{
+		Name:     "iban",
+		Regexp:   regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]){11,30}\b`),
+		Validate: ibanValid,
+	}
+)
+
+// DefaultRedactedKeys are key names that usually contain secrets.
+var DefaultRedactedKeys = []string{"password", "passwd", "secret", "token", "authorization", "api_key", "apikey", "iban"}
+
+// RedactorConfig configures a Redactor.
+type RedactorConfig struct {
+	// Keys are matched case-insensitively against log keys and keys of nested objects.
+	Keys []string
+	// Patterns are searched in string values, matches are replaced.
+	Patterns []ValuePattern
+	// HMACKey replaces redacted values by a keyed hash instead of Redacted, if set.
+	// Equal values produce equal hashes, so records can still be correlated.
+	HMACKey []byte
+}
+
+// Redactor replaces sensitive values before they are written.
+type Redactor struct {
+	keys     map[string]struct{}
+	patterns []ValuePattern
+	hmacKey  []byte
+}
+
+// NewRedactor creates a Redactor from the given config.
+func NewRedactor(cfg RedactorConfig) *Redactor {
+	r := &Redactor{
+		keys:     make(map[string]struct{}, len(cfg.Keys)),
+		patterns: cfg.Patterns,
+		hmacKey:  cfg.HMACKey,
+	}
+	for _, k := range cfg.Keys {
+		r.keys[strings.ToLower(k)] = struct{}{}
+	}
+	return r
+}
+
+// WithRedactor enables redaction of sensitive keys and values.
+func WithRedactor(r *Redactor) Option {
+	return func(l *instance) {
+		l.encoder.redactor = r
+	}
+}
+
+func (r *Redactor) matchesKey(key string) bool {
+	if len(r.keys) == 0 {
+		return false
+	}
+	if _, ok := r.keys[key]; ok {
+		return true
+	}
+	_, ok := r.keys[strings.ToLower(key)]
+	return ok
+}
+
+// redactString replaces all pattern matches in s.
+func (r *Redactor) redactString(s string) string {
+	for _, p := range r.patterns {
+		if !p.Regexp.MatchString(s) {
+			continue
+		}
+		s = p.Regexp.ReplaceAllStringFunc(s, func(match string) string {
+			if p.Validate != nil && !p.Validate(match) {
+				return match
+			}
+			return r.replacement(match)
+		})
+	}
+	return s
+}
+
+func (r *Redactor) replacement(value string) string {
+	if len(r.hmacKey) == 0 {
+		return Redacted
+	}
+	mac := hmac.New(sha256.New, r.hmacKey)
+	mac.Write([]byte(value))
+	return "[HMAC:" + hex.EncodeToString(mac.Sum(nil)[:16]) + "]"
+}
+
+// writeRedacted writes the replacement for a value whose key is sensitive.
+func (r *Redactor) writeRedacted(sw *StackWriter, value interface{}) (n int, err error) {
+	if len(r.hmacKey) == 0 {
+		return sw.WriteJSONString(Redacted)
+	}
+	if s, ok := value.(string); ok {
+		return sw.WriteJSONString(r.replacement(s))
+	}
+	var buf bytes.Buffer
+	tmp := MakeStackWriter(&buf)
+	if _, err := defaultEncoder.encodeValue(&tmp, value); err != nil {
+		return 0, err
+	}
+	if err := tmp.Flush(); err != nil {
+		return 0, err
+	}
+	return sw.WriteJSONString(r.replacement(buf.String()))
+}
+
+// writeJSON copies the JSON document in data to sw and redacts values of sensitive keys
+// in nested objects as well as pattern matches in strings. The order of object keys is
+// preserved.
+func (r *Redactor) writeJSON(sw *StackWriter, data []byte) (n int, err error) {
+	type frame struct {
+		object bool
+		count  int
+	}
+	var stack []frame
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	write := func(s string) {
+		if err != nil {
+			return
+		}
+		var nw int
+		nw, err = sw.Write(s)
+		n += nw
+	}
+	writeString := func(s string) {
+		if err != nil {
+			return
+		}
+		var nw int
+		nw, err = sw.WriteJSONString(s)
+		n += nw
+	}
+
+	for err == nil {
+		tok, tokErr := dec.Token()
+		if tokErr != nil {
+			break
+		}
+
+		if d, ok := tok.(json.Delim); ok && (d == '}' || d == ']') {
+			stack = stack[:len(stack)-1]
+			write(d.String())
+			continue
+		}
+
+		if len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			if top.count > 0 && (!top.object || top.count%2 == 0) {
+				write(",")
+			}
+			top.count++
+
+			if top.object && top.count%2 == 1 {
+				key := tok.(string)
+				writeString(key)
+				write(":")
+				if r.matchesKey(key) {
+					var raw json.RawMessage
+					if decErr := dec.Decode(&raw); decErr != nil {
+						return n, decErr
+					}
+					top.count++
+					var s string
+					if json.Unmarshal(raw, &s) != nil {
+						s = string(raw)
+					}
+					writeString(r.replacement(s))
+				}
+				continue
+			}
+		}
+
+		switch v := tok.(type) {
+		case json.Delim:
+			stack = append(stack, frame{object: v == '{'})
+			write(v.String())
+		case string:
+			writeString(r.redactString(v))
+		case json.Number:
+			write(v.String())
+		case bool:
+			if v {
+				write("true")
+			} else {
+				write("false")
+			}
+		case nil:
+			write("null")
+		}
+	}
+	return n, err
+}
+
+// luhnValid checks the Luhn check digit of a number, ignoring spaces and dashes.
+func luhnValid(number string) bool {
+	sum := 0
+	digits := 0
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		d := int(c - '0')
+		if digits%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+	}
+	return digits >= 13 && sum%10 == 0
+}
+
+// ibanValid checks the ISO 13616 mod-97 checksum of an IBAN, ignoring spaces.
+func ibanValid(iban string) bool {
+	iban = strings.ReplaceAll(iban, " ", "")
+	if len(iban) < 15 {
+		return false
+	}
+	rearranged := iban[4:] + iban[:4]
+	remainder := 0
+	for i := 0; i < len(rearranged); i++ {
+		c := rearranged[i]
+		switch {
+		case c >= '0' && c <= '9':
+			remainder = (remainder*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			remainder = (remainder*100 + int(c-'A') + 10) % 97
+		default:
+			return false
+		}
+	}
+	return remainder == 1
+}
@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// Sampling limits the records with the same level and message: per Tick, the first First
+// records are written, after that only every Thereafter-th. Records of level ERROR and above
+// are never dropped. The zero value writes all records. Tick defaults to one second, it is
+// measured by the clock of the logger, see WithClock.
+type Sampling struct {
+	First      int
+	Thereafter int
+	Tick       time.Duration
+}
+
+// WithSampling drops records that repeat too often, see Sampling.
+func WithSampling(s Sampling) Option {
+	return func(l *instance) {
+		l.sampler = newSampler(s)
+	}
+}
+
+type sampler struct {
+	mutex  sync.Mutex
+	config Sampling
+	counts map[uint64]int
+	reset  time.Time
+}
+
+// defaultSamplingTick is used if Sampling.Tick is not positive. Resetting the counters for
+// every record would disable sampling.
+const defaultSamplingTick = time.Second
+
+func newSampler(s Sampling) *sampler {
+	sampler := &sampler{}
+	sampler.set(s)
+	return sampler
+}
+
+// set replaces the configuration and resets the counters.
+func (s *sampler) set(config Sampling) {
+	if config.Tick <= 0 {
+		config.Tick = defaultSamplingTick
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.config = config
+	s.counts = map[uint64]int{}
+}
+
+// sample returns true if the record should be written. The ticks are measured by the clock
+// of the logger, time.Now if it is nil.
+func (s *sampler) sample(level Level, message string, clock Clock) bool {
+	if level >= LevelError {
+		return true
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.config.First <= 0 && s.config.Thereafter <= 0 {
+		return true
+	}
+
+	if now := clockNow(clock); now.Sub(s.reset) >= s.config.Tick {
+		for k := range s.counts {
+			delete(s.counts, k)
+		}
+		s.reset = now
+	}
+
+	// The message is hashed, because keeping it in the map would make it escape.
+	key := samplingKey(level, message)
+	n := s.counts[key] + 1
+	s.counts[key] = n
+	if n <= s.config.First {
+		return true
+	}
+	return s.config.Thereafter > 0 && (n-s.config.First)%s.config.Thereafter == 0
+}
+
+// samplingKey returns the FNV-1a hash of level and message.
+func samplingKey(level Level, message string) uint64 {
+	const prime = 1099511628211
+	h := uint64(14695981039346656037) ^ uint64(level)
+	h *= prime
+	for i := 0; i < len(message); i++ {
+		h ^= uint64(message[i])
+		h *= prime
+	}
+	return h
+}
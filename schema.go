@@ -0,0 +1,227 @@
+package logger
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Schema defines the keys of the fields every record consists of and the spelling of the
+// level values. Empty keys omit the field, except LevelKey and MessageKey, which default
+// to "level" and "message".
+type Schema struct {
+	TimeKey       string
+	LevelKey      string
+	MessageKey    string
+	LoggerKey     string
+	CallerFuncKey string
+	// CallerFileKey is written as "file:line", unless CallerLineKey is set.
+	CallerFileKey string
+	CallerLineKey string
+	// SourceLocationKey writes the caller as object {"file": ..., "line": ..., "function": ...}
+	// instead of the caller keys.
+	SourceLocationKey string
+	StackTraceKey     string
+	// LevelNames replaces the names of levels, levels that are missing use Level.String.
+	LevelNames map[Level]string
+}
+
+var (
+	// DefaultSchema is used if no schema is configured.
+	DefaultSchema = Schema{
+		TimeKey:       "ts",
+		LevelKey:      "level",
+		MessageKey:    "message",
+		LoggerKey:     "logger",
+		CallerFuncKey: "caller_func",
+		CallerFileKey: "caller_file",
+		StackTraceKey: "stacktrace",
+	}
+	// ECSSchema follows the Elastic Common Schema.
+	ECSSchema = Schema{
+		TimeKey:       "@timestamp",
+		LevelKey:      "log.level",
+		MessageKey:    "message",
+		LoggerKey:     "log.logger",
+		CallerFuncKey: "log.origin.function",
+		CallerFileKey: "log.origin.file.name",
+		CallerLineKey: "log.origin.file.line",
+		StackTraceKey: "error.stack_trace",
+		LevelNames:    lowerCaseLevelNames(),
+	}
+	// GCPSchema follows the structured logging format of Google Cloud Logging.
+	GCPSchema = Schema{
+		TimeKey:           "time",
+		LevelKey:          "severity",
+		MessageKey:        "message",
+		LoggerKey:         "logger",
+		SourceLocationKey: "logging.googleapis.com/sourceLocation",
+		StackTraceKey:     "stack_trace",
+		LevelNames: map[Level]string{
+			LevelTrace: "DEBUG",
+			LevelDebug: "DEBUG",
+			LevelInfo:  "INFO",
+			LevelWarn:  "WARNING",
+			LevelError: "ERROR",
+			LevelFatal: "CRITICAL",
+			LevelPanic: "ALERT",
+		},
+	}
+	// DatadogSchema uses the reserved and standard attributes of Datadog.
+	DatadogSchema = Schema{
+		TimeKey:       "timestamp",
+		LevelKey:      "status",
+		MessageKey:    "message",
+		LoggerKey:     "logger.name",
+		CallerFuncKey: "logger.method_name",
+		CallerFileKey: "caller_file",
+		StackTraceKey: "error.stack",
+		LevelNames: map[Level]string{
+			LevelTrace: "debug",
+			LevelDebug: "debug",
+			LevelInfo:  "info",
+			LevelWarn:  "warning",
+			LevelError: "error",
+			LevelFatal: "critical",
+			LevelPanic: "emergency",
+		},
+	}
+)
+
+func lowerCaseLevelNames() map[Level]string {
+	names := map[Level]string{}
+	for _, level := range []Level{LevelTrace, LevelDebug, LevelInfo, LevelWarn, LevelError, LevelFatal, LevelPanic} {
+		names[level] = strings.ToLower(level.String())
+	}
+	return names
+}
+
+// WithSchema sets the keys of the record fields and the level names, e.g. WithSchema(ECSSchema).
+func WithSchema(s Schema) Option {
+	return func(l *instance) {
+		l.schema = compileSchema(s)
+	}
+}
+
+// schema is a Schema with pre-encoded keys. Every key except the first is prefixed by ", ".
+type schema struct {
+	time           string
+	level          string
+	message        string
+	logger         string
+	callerFunc     string
+	callerFile     string
+	callerLine     string
+	sourceLocation string
+	stackTrace     string
+	hasCaller      bool
+	levelNames     map[Level]string
+	reserved       []string
+	source         Schema
+}
+
+var defaultSchema = compileSchema(DefaultSchema)
+
+func compileSchema(s Schema) *schema {
+	if s.LevelKey == "" {
+		s.LevelKey = DefaultSchema.LevelKey
+	}
+	if s.MessageKey == "" {
+		s.MessageKey = DefaultSchema.MessageKey
+	}
+
+	c := &schema{levelNames: make(map[Level]string, len(s.LevelNames)), source: s}
+	for level, name := range s.LevelNames {
+		c.levelNames[level] = name
+	}
+	key := func(k string) string {
+		if k == "" {
+			return ""
+		}
+		c.reserved = append(c.reserved, k)
+		encoded, _ := json.Marshal(k)
+		return ", " + string(encoded) + ": "
+	}
+
+	if s.TimeKey != "" {
+		c.time = "{" + key(s.TimeKey)[2:]
+		c.level = key(s.LevelKey)
+	} else {
+		c.level = "{" + key(s.LevelKey)[2:]
+	}
+	c.message = key(s.MessageKey)
+	c.logger = key(s.LoggerKey)
+	if s.SourceLocationKey != "" {
+		c.sourceLocation = key(s.SourceLocationKey)
+	} else {
+		c.callerFunc = key(s.CallerFuncKey)
+		c.callerFile = key(s.CallerFileKey)
+		if c.callerFile != "" {
+			c.callerLine = key(s.CallerLineKey)
+		}
+	}
+	c.stackTrace = key(s.StackTraceKey)
+	c.hasCaller = c.sourceLocation != "" || c.callerFunc != "" || c.callerFile != ""
+	return c
+}
+
+// withoutTime returns the schema without timestamp.
+func (s *schema) withoutTime() *schema {
+	source := s.source
+	source.TimeKey = ""
+	return compileSchema(source)
+}
+
+// levelName returns the spelling of level.
+func (s *schema) levelName(level Level) string {
+	if len(s.levelNames) > 0 {
+		if name, ok := s.levelNames[level]; ok {
+			return name
+		}
+	}
+	return level.String()
+}
+
+func (s *schema) isReserved(key string) bool {
+	for _, k := range s.reserved {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// writeCaller writes the caller fields of a record.
+func (s *schema) writeCaller(sw *StackWriter, funcName string, fileName string, line int) {
+	if s.sourceLocation != "" {
+		sw.Write(s.sourceLocation)
+		sw.Write("{\"file\": ")
+		sw.WriteJSONString(fileName)
+		sw.Write(", \"line\": \"")
+		sw.Write(strconv.Itoa(line))
+		sw.Write("\", \"function\": ")
+		sw.WriteJSONString(funcName)
+		sw.Write("}")
+		return
+	}
+
+	if s.callerFunc != "" {
+		sw.Write(s.callerFunc)
+		sw.WriteJSONString(funcName)
+	}
+	if s.callerFile == "" {
+		return
+	}
+	sw.Write(s.callerFile)
+	if s.callerLine != "" {
+		sw.WriteJSONString(fileName)
+		sw.Write(s.callerLine)
+		sw.Write(strconv.Itoa(line))
+		return
+	}
+	sw.Write("\"")
+	sw.WriteEscaped(fileName)
+	sw.Write(":")
+	sw.Write(strconv.Itoa(line))
+	sw.Write("\"")
+}
@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// logSchemaRecords writes the records that are compared to the golden files of the schemas.
+func logSchemaRecords(logger Logger) {
+	logger.Info("Started", "port", 8080)
+	logger.Named("payment").Debug("Authorized", "amount", 12.5)
+	logger.Warn("Slow response", "duration_ms", 1200)
+	logger.Log(LevelInfo+1, "Custom level")
+	logger.Log(LevelFatal, "Cannot recover")
+}
+
+func TestSchemas_Golden(t *testing.T) {
+	schemas := map[string]Schema{
+		"default": DefaultSchema,
+		"ecs":     ECSSchema,
+		"gcp":     GCPSchema,
+		"datadog": DatadogSchema,
+	}
+	for name, schema := range schemas {
+		t.Run(name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			logSchemaRecords(NewWithWriter(LvlDebug, out, WithSchema(schema), WithClock(NewFakeClock(goldenTime))))
+
+			assertValidRecords(t, out.String())
+			assertGolden(t, "schema_"+name, normalizeRecords(out.String()))
+		})
+	}
+}
+
+func TestSchema_OmittedKeys(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := NewWithWriter(LvlInfo, out, WithSchema(Schema{MessageKey: "msg", CallerFuncKey: "func"}))
+	logger.Named("payment").Error("Failed", "a", 1)
+
+	expected := `{"level": "ERROR", "msg": "Failed", "a": 1, "func": "github.com/fond-of-vertigo/logger.TestSchema_OmittedKeys"}` + "\n"
+	if out.String() != expected {
+		t.Errorf("Output is incorrect, Expected\n%s\nActual\n%s", expected, out.String())
+	}
+}
+
+func TestSchema_ReservedKeys(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := NewWithWriter(LvlInfo, out, WithSchema(ECSSchema), WithKeyPolicy(KeyPolicy{ReservedKeyPrefix: "fields."}))
+	logger.Info("msg", "log.level", "x", "level", "y")
+
+	if !strings.HasSuffix(out.String(), `"message": "msg", "fields.log.level": "x", "level": "y"}`+"\n") {
+		t.Errorf("Reserved keys are incorrect: %s", out.String())
+	}
+}
@@ -3,12 +3,22 @@ package logger
 import (
 	"fmt"
 	"io"
+	"sync"
 )
 
 type StackWriter struct {
 	w          io.Writer
 	buf        [bufSize]byte
 	bufDataLen int
+
+	// While a value that may be rolled back is written, full buffers are kept in spill
+	// instead of being flushed to w.
+	spill    *[]byte
+	spilling bool
+
+	// err is the first error of w. Like bufio.Writer, nothing is written after an error,
+	// because the lost bytes would leave a gap in the record.
+	err error
 }
 
 const bufSize = 1024
@@ -104,6 +114,9 @@ func (sw *StackWriter) WriteEscaped(str string) (n int, err error) {
 }
 
 func (sw *StackWriter) Write(s string) (n int, err error) {
+	if sw.err != nil {
+		return 0, sw.err
+	}
 	lenToWrite := len(s)
 	for lenToWrite > 0 {
 		endIndex := sw.bufDataLen + lenToWrite
@@ -140,7 +153,7 @@ func (sw *StackWriter) Write(s string) (n int, err error) {
 
 func (sw *StackWriter) Flush() error {
 	if sw.bufDataLen == 0 {
-		return nil
+		return sw.err
 	}
 
 	bytesToFlush := sw.bufDataLen
@@ -160,11 +173,92 @@ func (sw *StackWriter) rawFlush() (n int, err error) {
 		return 0, nil
 	}
 
+	if sw.spilling {
+		if sw.spill == nil {
+			sw.spill = spillPool.Get().(*[]byte)
+		}
+		*sw.spill = append(*sw.spill, sw.buf[:sw.bufDataLen]...)
+		n = sw.bufDataLen
+		sw.bufDataLen = 0
+		return n, nil
+	}
+
 	// We need a slice var for the unsafe pointer:
 	slice := sw.buf[:sw.bufDataLen]
 
 	// Reset the bufDataLen in any case, even if not all bytes were written.
 	sw.bufDataLen = 0
 
-	return sw.w.Write(noescape_bytearray(&slice))
+	if sw.err != nil {
+		return 0, sw.err
+	}
+	n, err = sw.w.Write(noescape_bytearray(&slice))
+	if err == nil && n < len(slice) {
+		err = io.ErrShortWrite
+	}
+	sw.err = err
+	return n, err
+}
+
+var spillPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 4*bufSize)
+		return &b
+	},
+}
+
+// writeMark is a position in the output that can be restored with rollback.
+type writeMark struct {
+	spillLen    int
+	bufDataLen  int
+	wasSpilling bool
+}
+
+// mark returns the current position. Until commit or rollback is called with the mark,
+// nothing more is written to the underlying writer.
+func (sw *StackWriter) mark() writeMark {
+	m := writeMark{
+		bufDataLen:  sw.bufDataLen,
+		wasSpilling: sw.spilling,
+	}
+	if sw.spill != nil {
+		m.spillLen = len(*sw.spill)
+	}
+	sw.spilling = true
+	return m
+}
+
+// rollback discards everything that was written after m.
+func (sw *StackWriter) rollback(m writeMark) error {
+	if sw.spill != nil && len(*sw.spill) > m.spillLen {
+		// The buffer at the time of the mark was moved to spill, restore it.
+		copy(sw.buf[:], (*sw.spill)[m.spillLen:m.spillLen+m.bufDataLen])
+		*sw.spill = (*sw.spill)[:m.spillLen]
+	}
+	sw.bufDataLen = m.bufDataLen
+	return sw.commit(m)
+}
+
+// commit keeps everything that was written after m.
+func (sw *StackWriter) commit(m writeMark) error {
+	sw.spilling = m.wasSpilling
+	if sw.spilling || sw.spill == nil {
+		return nil
+	}
+
+	spill := sw.spill
+	sw.spill = nil
+	defer func() {
+		*spill = (*spill)[:0]
+		spillPool.Put(spill)
+	}()
+	if len(*spill) == 0 || sw.err != nil {
+		return sw.err
+	}
+	n, err := sw.w.Write(*spill)
+	if err == nil && n < len(*spill) {
+		err = io.ErrShortWrite
+	}
+	sw.err = err
+	return err
 }
@@ -3,6 +3,7 @@ package logger
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"io"
 	"os"
 	"strings"
@@ -131,6 +132,48 @@ func TestStackWriter_WriteEscaped(t *testing.T) {
 	}
 }
 
+func TestStackWriter_MarkCommitRollback(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   int
+		inner    int
+		rollback bool
+	}{
+		{name: "Commit within buffer", before: 10, inner: 10},
+		{name: "Commit across buffer", before: bufSize - 5, inner: bufSize * 3},
+		{name: "Rollback within buffer", before: 10, inner: 10, rollback: true},
+		{name: "Rollback across buffer", before: bufSize - 5, inner: bufSize * 3, rollback: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := bytes.NewBufferString("")
+			sw := MakeStackWriter(out)
+			before := makeString(tt.before)
+			inner := strings.Repeat("x", tt.inner)
+
+			sw.Write(before)
+			m := sw.mark()
+			sw.Write(inner)
+			if out.Len() > 0 {
+				t.Errorf("Data was flushed before commit")
+			}
+			want := before + inner
+			if tt.rollback {
+				sw.rollback(m)
+				want = before
+			} else {
+				sw.commit(m)
+			}
+			sw.Write("END")
+			sw.Flush()
+
+			if out.String() != want+"END" {
+				t.Errorf("out.String does not equal want:\nWant: %s\nGot.: %s", want+"END", out.String())
+			}
+		})
+	}
+}
+
 func TestStackWriter_ZeroAlloc(t *testing.T) {
 	longString := makeString(16 * 1024)
 	allocs := testing.AllocsPerRun(1, func() {
@@ -167,6 +210,133 @@ func TestStackWriter_WritesEscaped_ZeroAlloc(t *testing.T) {
 	}
 }
 
+func TestStackWriter_WriteError(t *testing.T) {
+	for name, w := range map[string]*chunkWriter{
+		"short write": {size: 10},
+		"error":       {size: bufSize, limit: 10},
+	} {
+		t.Run(name, func(t *testing.T) {
+			sw := MakeStackWriter(w)
+			sw.Write(makeString(bufSize))
+			if _, err := sw.Write("x"); err == nil {
+				t.Errorf("Write after a failed flush should return the error")
+			}
+			if err := sw.Flush(); err == nil {
+				t.Errorf("Flush after a failed flush should return the error")
+			}
+			if w.String() != makeString(10) {
+				t.Errorf("Nothing should be written after the failed write, Actual %q", w.String())
+			}
+		})
+	}
+}
+
+func FuzzStackWriter_WriteJSONString(f *testing.F) {
+	f.Add("", uint16(0), uint16(0), uint8(0))
+	f.Add("abc äöü 🙂", uint16(bufSize-1), uint16(3), uint8(1))
+	f.Add(string([]byte{0, 1, 8, 9, 10, 12, 13, 27, 31, '"', '\\', 127}), uint16(bufSize-3), uint16(5), uint8(2))
+	f.Add("\u2028\u2029 <&> \xff\xc3 \xe2\x82", uint16(bufSize-10), uint16(7), uint8(7))
+	f.Add(strings.Repeat("\"\n", bufSize), uint16(1), uint16(bufSize), uint8(63))
+	f.Fuzz(func(t *testing.T, s string, offset uint16, split uint16, chunkSize uint8) {
+		prefix := makeString(int(offset) % bufSize)
+
+		out := &bytes.Buffer{}
+		sw := MakeStackWriter(out)
+		sw.Write(prefix)
+		if _, err := sw.WriteJSONString(s); err != nil {
+			t.Fatal(err)
+		}
+		if err := sw.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		encoded := strings.TrimPrefix(out.String(), prefix)
+		if len(encoded) != out.Len()-len(prefix) {
+			t.Fatalf("Prefix was changed: %q", out.String())
+		}
+		assertSingleLine(t, encoded)
+		var decoded string
+		if err := json.Unmarshal([]byte(encoded), &decoded); err != nil {
+			t.Fatalf("Output is not a valid JSON string: %v\n%q", err, encoded)
+		}
+		if decoded != string([]rune(s)) {
+			t.Fatalf("String does not round-trip, Expected %q, Actual %q", s, decoded)
+		}
+
+		// Escaping works byte by byte, so splitting the string must not change the output. A
+		// writer that fails must be reported and must not corrupt the output.
+		chunked := &chunkWriter{size: int(chunkSize)*8 + 1, limit: int(split) % (2 * bufSize)}
+		sw = MakeStackWriter(chunked)
+		k := int(split) % (len(s) + 1)
+		_, err1 := sw.Write(prefix)
+		_, err2 := sw.Write("\"")
+		_, err3 := sw.WriteEscaped(s[:k])
+		_, err4 := sw.WriteEscaped(s[k:])
+		_, err5 := sw.Write("\"")
+		err6 := sw.Flush()
+		if chunked.errors > 0 && err1 == nil && err2 == nil && err3 == nil && err4 == nil && err5 == nil && err6 == nil {
+			t.Fatalf("Failed write was not reported, output %q", chunked.Bytes())
+		}
+		if chunked.errors == 0 && (err1 != nil || err2 != nil || err3 != nil || err4 != nil || err5 != nil || err6 != nil) {
+			t.Fatalf("Unexpected error: %v %v %v %v %v %v", err1, err2, err3, err4, err5, err6)
+		}
+		assertWriterOutput(t, chunked, out.Bytes())
+	})
+}
+
+// errInjected is returned by chunkWriter after limit bytes.
+var errInjected = errors.New("injected error")
+
+// chunkWriter accepts at most size bytes per write and reports a short write for the rest,
+// like a non-blocking pipe or socket. If limit is not 0, it fails after limit bytes.
+type chunkWriter struct {
+	bytes.Buffer
+	size  int
+	limit int
+	// errors counts the writes that failed.
+	errors int
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	if w.limit > 0 && w.Len()+len(p) > w.limit {
+		n, _ := w.Buffer.Write(p[:w.limit-w.Len()])
+		w.errors++
+		return n, errInjected
+	}
+	if len(p) > w.size {
+		n, _ := w.Buffer.Write(p[:w.size])
+		w.errors++
+		return n, io.ErrShortWrite
+	}
+	return w.Buffer.Write(p)
+}
+
+// assertWriterOutput fails the test if the output of a chunkWriter differs from expected. If
+// a write failed, the output must be a prefix of expected: nothing may be written after the
+// lost bytes.
+func assertWriterOutput(t *testing.T, w *chunkWriter, expected []byte) {
+	t.Helper()
+	if w.errors == 0 {
+		if !bytes.Equal(w.Bytes(), expected) {
+			t.Fatalf("Chunked output differs, Expected\n%q\nActual\n%q", expected, w.Bytes())
+		}
+		return
+	}
+	if !bytes.HasPrefix(expected, w.Bytes()) {
+		t.Fatalf("Output after failed write is corrupt, Expected prefix of\n%q\nActual\n%q", expected, w.Bytes())
+	}
+}
+
+// assertSingleLine fails the test if s contains line breaks or other control characters.
+func assertSingleLine(t *testing.T, s string) {
+	t.Helper()
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 {
+			t.Fatalf("Output contains control character %#x at %d: %q", s[i], i, s)
+		}
+	}
+}
+
 func mustMarshalJSONString(str string) string {
 	j, err := json.Marshal(str)
 	if err != nil {
@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"os"
+	"runtime/debug"
+	"strings"
+)
+
+// WithFields adds fields that are written in every record. The fields are encoded once when
+// the logger is created, so they cost nothing per record. A later field replaces an earlier
+// field with the same key.
+func WithFields(fields ...Field) Option {
+	return func(l *instance) {
+		for _, f := range fields {
+			l.setStaticField(f)
+		}
+	}
+}
+
+// WithService adds the fields service, version and env. Empty values are skipped.
+func WithService(service, version, env string) Option {
+	return func(l *instance) {
+		for _, kv := range [][2]string{{"service", service}, {"version", version}, {"env", env}} {
+			if kv[1] != "" {
+				l.setStaticField(String(kv[0], kv[1]))
+			}
+		}
+	}
+}
+
+// WithHostname adds the field hostname, if the hostname is known.
+func WithHostname() Option {
+	return func(l *instance) {
+		if hostname, err := os.Hostname(); err == nil {
+			l.setStaticField(String("hostname", hostname))
+		}
+	}
+}
+
+// WithPID adds the field pid with the process id.
+func WithPID() Option {
+	return func(l *instance) {
+		l.setStaticField(Int("pid", os.Getpid()))
+	}
+}
+
+// WithBuildInfo adds the fields version and revision from the build information of the
+// binary: the version of the main module and the VCS revision, with suffix "-dirty" if
+// there were uncommitted changes. Unknown values are skipped.
+func WithBuildInfo() Option {
+	return func(l *instance) {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		if v := info.Main.Version; v != "" && v != "(devel)" {
+			l.setStaticField(String("version", v))
+		}
+
+		var revision string
+		var modified bool
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				revision = setting.Value
+			case "vcs.modified":
+				modified = setting.Value == "true"
+			}
+		}
+		if revision != "" {
+			if modified {
+				revision += "-dirty"
+			}
+			l.setStaticField(String("revision", revision))
+		}
+	}
+}
+
+// WithKubernetes adds the fields pod and namespace from the environment variables POD_NAME
+// and NAMESPACE (or POD_NAMESPACE), which are usually set by the downward API:
+//
+//	env:
+//	  - name: POD_NAME
+//	    valueFrom: {fieldRef: {fieldPath: metadata.name}}
+//	  - name: NAMESPACE
+//	    valueFrom: {fieldRef: {fieldPath: metadata.namespace}}
+func WithKubernetes() Option {
+	return func(l *instance) {
+		if pod := os.Getenv("POD_NAME"); pod != "" {
+			l.setStaticField(String("pod", pod))
+		}
+		namespace := os.Getenv("NAMESPACE")
+		if namespace == "" {
+			namespace = os.Getenv("POD_NAMESPACE")
+		}
+		if namespace != "" {
+			l.setStaticField(String("namespace", namespace))
+		}
+	}
+}
+
+func (l *instance) setStaticField(f Field) {
+	for i := range l.fields {
+		if l.fields[i].Key == f.Key {
+			l.fields[i] = f
+			return
+		}
+	}
+	l.fields = append(l.fields, f)
+}
+
+// encodeStaticFields encodes the fields like key/value pairs of a record, with leading ", ".
+func (l *instance) encodeStaticFields() {
+	l.staticFieldParts = make([]string, len(l.fields))
+	for i := range l.fields {
+		var b strings.Builder
+		sw := MakeStackWriter(&b)
+		sw.Write(", ")
+		l.writeKey(&sw, l.fields[i].Key)
+		sw.Write(": ")
+		l.encoder.encodeTypedField(&sw, &l.fields[i])
+		sw.Flush()
+		l.staticFieldParts[i] = b.String()
+	}
+	l.staticFields = strings.Join(l.staticFieldParts, "")
+}
+
+// writeStaticFields writes the static fields whose keys are not used by the record, see
+// KeyPolicy.Deduplicate.
+func (l *instance) writeStaticFields(sw *StackWriter, fields []Field, keysAndValues []interface{}) {
+	for i := range l.fields {
+		if !isKeyRepeated(l.fields[i].Key, keysAndValues, fields) {
+			sw.Write(l.staticFieldParts[i])
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWithFields(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := NewWithWriter(LvlInfo, out,
+		WithService("payment", "1.2.3", "prod"),
+		WithFields(String("env", "staging"), Int("shard", 3), String("token", "secret")),
+		WithRedactor(NewRedactor(RedactorConfig{Keys: []string{"token"}})),
+	)
+
+	logger.Info("Started", "port", 8080)
+	logger.Named("client").Info("Connected")
+
+	expected := []string{
+		`"level": "INFO", "message": "Started", "service": "payment", "version": "1.2.3", "env": "staging", "shard": 3, "token": "[REDACTED]", "port": 8080}`,
+		`"level": "INFO", "message": "Connected", "logger": "client", "service": "payment", "version": "1.2.3", "env": "staging", "shard": 3, "token": "[REDACTED]"}`,
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	for i, line := range lines {
+		if !strings.HasSuffix(line, expected[i]) {
+			t.Errorf("Record is incorrect, Expected suffix\n%s\nActual\n%s", expected[i], line)
+		}
+	}
+}
+
+func TestWithService_EmptyValues(t *testing.T) {
+	logger := NewWithWriter(LvlInfo, io.Discard, WithService("payment", "", ""))
+	if logger.staticFields != `, "service": "payment"` {
+		t.Errorf("Static fields are incorrect: %s", logger.staticFields)
+	}
+}
+
+func TestWithHostnameAndPID(t *testing.T) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Skip(err)
+	}
+	logger := NewWithWriter(LvlInfo, io.Discard, WithHostname(), WithPID())
+	expected := `, "hostname": ` + strconv.Quote(hostname) + `, "pid": ` + strconv.Itoa(os.Getpid())
+	if logger.staticFields != expected {
+		t.Errorf("Static fields are incorrect, Expected %s, Actual %s", expected, logger.staticFields)
+	}
+}
+
+func TestWithKubernetes(t *testing.T) {
+	t.Setenv("POD_NAME", "payment-7d9f")
+	t.Setenv("NAMESPACE", "")
+	t.Setenv("POD_NAMESPACE", "shop")
+
+	logger := NewWithWriter(LvlInfo, io.Discard, WithKubernetes())
+	if logger.staticFields != `, "pod": "payment-7d9f", "namespace": "shop"` {
+		t.Errorf("Static fields are incorrect: %s", logger.staticFields)
+	}
+}
+
+func TestWithBuildInfo(t *testing.T) {
+	// Test binaries have no version or VCS information, the option must not add empty fields.
+	logger := NewWithWriter(LvlInfo, io.Discard, WithBuildInfo())
+	if strings.Contains(logger.staticFields, `""`) {
+		t.Errorf("Static fields contain empty values: %s", logger.staticFields)
+	}
+}
+
+func TestWithFields_Allocations(t *testing.T) {
+	logger := NewWithWriter(LvlInfo, io.Discard, WithService("payment", "1.2.3", "prod"), WithPID())
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.InfoF("Request done", Int("status", 200))
+	})
+	if allocs != 0 {
+		t.Errorf("Expected 0 allocations, Actual %f", allocs)
+	}
+}
+
+func TestWithFields_KeyPolicy(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := NewWithWriter(LvlInfo, out, WithService("payment", "", ""), WithFields(String("message", "static")),
+		WithKeyPolicy(KeyPolicy{Deduplicate: true, ReservedKeyPrefix: "fields."}))
+
+	logger.Info("Deduplicated", "service", "refund")
+	logger.InfoF("Deduplicated", String("service", "refund"))
+	logger.Named("client").Info("Kept")
+
+	expected := []string{
+		`"message": "Deduplicated", "fields.message": "static", "service": "refund"}`,
+		`"message": "Deduplicated", "fields.message": "static", "service": "refund"}`,
+		`"message": "Kept", "logger": "client", "service": "payment", "fields.message": "static"}`,
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d records, got\n%s", len(expected), out)
+	}
+	for i, line := range lines {
+		if !strings.HasSuffix(line, expected[i]) {
+			t.Errorf("Record is incorrect, Expected suffix\n%s\nActual\n%s", expected[i], line)
+		}
+	}
+}
+
+func TestWithFields_KeyPolicy_Panic(t *testing.T) {
+	logger := NewWithWriter(LvlInfo, io.Discard, WithService("payment", "", ""), WithKeyPolicy(KeyPolicy{Panic: true}))
+
+	defer func() {
+		r := recover()
+		if r == nil || !strings.Contains(r.(string), `"service"`) {
+			t.Errorf("Expected panic about the duplicate key service, got %v", r)
+		}
+	}()
+	logger.Info("Duplicate", "service", "refund")
+}
@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"log"
+	"runtime"
+	"strings"
+)
+
+// RedirectStdLog sends the output of the standard library log package to l. Every line is
+// written as record of the given level, the prefix, date, time and file of the log package
+// are removed. The flags of the log package are set to 0, because the records have their
+// own timestamp. Call restore to undo the redirection:
+//
+//	restore := logger.RedirectStdLog(log.Named("stdlib"), logger.LevelInfo)
+//	defer restore()
+//
+// log.Fatal and log.Panic still exit and panic, their records use the given level too.
+func RedirectStdLog(l Logger, level Level) (restore func()) {
+	flags := log.Flags()
+	prefix := log.Prefix()
+	output := log.Writer()
+
+	log.SetFlags(0)
+	log.SetOutput(&stdLogWriter{logger: l, level: level, prefix: prefix})
+	return func() {
+		log.SetFlags(flags)
+		log.SetOutput(output)
+	}
+}
+
+// NewStdLogger returns a *log.Logger that writes every line as record of the given level,
+// for APIs that require a *log.Logger, e.g. http.Server.ErrorLog.
+func NewStdLogger(l Logger, level Level) *log.Logger {
+	return log.New(&stdLogWriter{logger: l, level: level}, "", 0)
+}
+
+// stdLogWriter parses the lines of a *log.Logger. The flags and prefix of the *log.Logger
+// cannot be read while it writes, because it holds its lock during Write. Instead the
+// prefix is known in advance and the headers of the flags are recognized by their format,
+// so they are removed even if the flags are changed later.
+type stdLogWriter struct {
+	logger Logger
+	level  Level
+	prefix string
+}
+
+func (w *stdLogWriter) Write(p []byte) (int, error) {
+	lines := strings.Split(strings.TrimRight(string(p), "\r\n"), "\n")
+	for i, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		if i == 0 {
+			line = w.trimHeader(line)
+		}
+		if line != "" {
+			w.logger.Log(w.level, line)
+		}
+	}
+	return len(p), nil
+}
+
+// stdLogWriteFunc is the name of stdLogWriter.Write. It is the caller of records written by the
+// standard library log package, the actual caller is found by stdLogCaller.
+const stdLogWriteFunc = "github.com/fond-of-vertigo/logger.(*stdLogWriter).Write"
+
+// stdLogCaller returns the caller of the log package function that called stdLogWriter.Write.
+func stdLogCaller() (funcName string, file string, line int) {
+	pcs := make([]uintptr, 16)
+	// Skip runtime.Callers, stdLogCaller, retrieveCallInfo, log, Log and Write.
+	n := runtime.Callers(6, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "log.") {
+			return frame.Function, frame.File, frame.Line
+		}
+		if !more {
+			return "", "", -1
+		}
+	}
+}
+
+// trimHeader removes the prefix, date, time and file that log.Logger writes in front of
+// the message, see log.Ldate, log.Ltime, log.Lmicroseconds, log.Lshortfile and log.Lmsgprefix.
+func (w *stdLogWriter) trimHeader(line string) string {
+	line = strings.TrimPrefix(line, w.prefix)
+	// 2009/01/23 01:23:23.123123 /a/b/c/d.go:23: message
+	if matchesPattern(line, "0000/00/00 ") {
+		line = line[len("0000/00/00 "):]
+	}
+	if matchesPattern(line, "00:00:00.000000 ") {
+		line = line[len("00:00:00.000000 "):]
+	} else if matchesPattern(line, "00:00:00 ") {
+		line = line[len("00:00:00 "):]
+	}
+	line = trimFile(line)
+	return strings.TrimPrefix(line, w.prefix)
+}
+
+// matchesPattern returns true if s starts with pattern, where 0 in the pattern matches any
+// digit.
+func matchesPattern(s string, pattern string) bool {
+	if len(s) < len(pattern) {
+		return false
+	}
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] == '0' {
+			if s[i] < '0' || s[i] > '9' {
+				return false
+			}
+		} else if s[i] != pattern[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// trimFile removes a leading "file.go:23: ". Messages without such a header are returned
+// unchanged.
+func trimFile(line string) string {
+	end := strings.Index(line, ": ")
+	if end < 0 {
+		return line
+	}
+	file, lineNumber, ok := cutLast(line[:end], ':')
+	if !ok || lineNumber == "" || strings.ContainsAny(file, " \t") || !strings.HasSuffix(file, ".go") && file != "???" {
+		return line
+	}
+	for i := 0; i < len(lineNumber); i++ {
+		if lineNumber[i] < '0' || lineNumber[i] > '9' {
+			return line
+		}
+	}
+	return line[end+len(": "):]
+}
+
+func cutLast(s string, sep byte) (before, after string, found bool) {
+	if i := strings.LastIndexByte(s, sep); i >= 0 {
+		return s[:i], s[i+1:], true
+	}
+	return s, "", false
+}
@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"bytes"
+	"log"
+	"testing"
+	"time"
+)
+
+func TestRedirectStdLog(t *testing.T) {
+	out := &bytes.Buffer{}
+	clock := NewFakeClock(time.Date(2022, 2, 1, 13, 1, 2, 0, time.UTC))
+	logger := NewWithWriter(LvlInfo, out, WithClock(clock))
+
+	log.SetPrefix("lib: ")
+	log.SetFlags(log.LstdFlags)
+	defer log.SetPrefix("")
+	restore := RedirectStdLog(logger.Named("stdlib"), LevelWarn)
+
+	log.Printf("first\n\n")
+	log.Println("multi\nline")
+	// Libraries may change the flags after the redirection.
+	log.SetFlags(log.Ldate | log.Lmicroseconds | log.Lshortfile | log.Lmsgprefix)
+	log.Print("with header")
+	log.SetFlags(log.Llongfile)
+	log.Print("long file")
+	restore()
+	log.SetOutput(&bytes.Buffer{})
+	log.Print("not redirected")
+
+	expected := `{"ts": "2022-02-01T13:01:02.000000Z", "level": "WARN", "message": "first", "logger": "stdlib", "caller_func": "github.com/fond-of-vertigo/logger.TestRedirectStdLog", "caller_file": "stdlog_test.go:20"}
+{"ts": "2022-02-01T13:01:02.000000Z", "level": "WARN", "message": "multi", "logger": "stdlib", "caller_func": "github.com/fond-of-vertigo/logger.TestRedirectStdLog", "caller_file": "stdlog_test.go:21"}
+{"ts": "2022-02-01T13:01:02.000000Z", "level": "WARN", "message": "line", "logger": "stdlib", "caller_func": "github.com/fond-of-vertigo/logger.TestRedirectStdLog", "caller_file": "stdlog_test.go:21"}
+{"ts": "2022-02-01T13:01:02.000000Z", "level": "WARN", "message": "with header", "logger": "stdlib", "caller_func": "github.com/fond-of-vertigo/logger.TestRedirectStdLog", "caller_file": "stdlog_test.go:24"}
+{"ts": "2022-02-01T13:01:02.000000Z", "level": "WARN", "message": "long file", "logger": "stdlib", "caller_func": "github.com/fond-of-vertigo/logger.TestRedirectStdLog", "caller_file": "stdlog_test.go:26"}
+`
+	if actual := normalizeRecords(out.String()); actual != expected {
+		t.Errorf("Output is incorrect, Expected\n%s\nActual\n%s", expected, actual)
+	}
+	if log.Flags() != log.LstdFlags {
+		t.Errorf("Flags were not restored: %d", log.Flags())
+	}
+}
+
+func TestNewStdLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	stdLogger := NewStdLogger(NewWithWriter(LvlInfo, out, WithTimeFormat(TimeNone)), LevelInfo)
+	stdLogger.Printf("http: TLS handshake error from %s: EOF", "127.0.0.1:1234")
+
+	expected := `{"level": "INFO", "message": "http: TLS handshake error from 127.0.0.1:1234: EOF"}` + "\n"
+	if out.String() != expected {
+		t.Errorf("Output is incorrect, Expected\n%s\nActual\n%s", expected, out.String())
+	}
+}
+
+func Test_stdLogWriter_trimHeader(t *testing.T) {
+	tests := []struct {
+		line   string
+		prefix string
+		want   string
+	}{
+		{line: "message", want: "message"},
+		{line: "2009/01/23 01:23:23 message", want: "message"},
+		{line: "2009/01/23 01:23:23.123123 message", want: "message"},
+		{line: "01:23:23 message", want: "message"},
+		{line: "2009/01/23 01:23:23 d.go:23: message", want: "message"},
+		{line: "/a/b/c/d.go:23: message: with colon", want: "message: with colon"},
+		{line: "???:0: message", want: "message"},
+		{line: "prefix: 2009/01/23 message", prefix: "prefix: ", want: "message"},
+		{line: "2009/01/23 d.go:23: prefix: message", prefix: "prefix: ", want: "message"},
+		{line: "2009/01/23message", want: "2009/01/23message"},
+		{line: "open config.go: no such file", want: "open config.go: no such file"},
+		{line: "error in x.go:abc: message", want: "error in x.go:abc: message"},
+	}
+	for _, tt := range tests {
+		w := &stdLogWriter{prefix: tt.prefix}
+		if got := w.trimHeader(tt.line); got != tt.want {
+			t.Errorf("trimHeader(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
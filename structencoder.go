@@ -0,0 +1,652 @@
+package logger
+
+import (
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// Struct fields can be tagged to control how they are logged:
+//
+//	type Customer struct {
+//		ID       string `log:"name=customer_id"`
+//		Password string `log:"-"`
+//		IBAN     string `log:"mask"`
+//		Email    string `log:"hash"`
+//	}
+//
+// "-" omits the field, "mask" replaces all but the last 4 characters with '*' (strings of
+// up to 8 characters completely, so that at least half of them is hidden), "hash" writes a
+// SHA-256 hash (or an HMAC if the logger's Redactor has an HMACKey) and "name=..." renames
+// the key. Options can be combined, e.g. `log:"name=email,hash"`.
+// The keys and fields are the same as for json.Marshal: json tags name fields and omit them
+// with "-" or "omitempty", and the fields of embedded structs are promoted.
+// Tags also apply to structs nested in fields, slices, arrays, maps and pointers, values
+// of such types are encoded field by field. Other values are encoded by json.Marshal.
+// Pointers, slices and maps that contain themselves are written as "<ERROR: cycle>" where
+// they repeat.
+const structTagName = "log"
+
+type fieldMode int
+
+const (
+	fieldPlain fieldMode = iota
+	fieldMask
+	fieldHash
+)
+
+type structPlan struct {
+	fields []fieldPlan
+	// needsAddr is true if fields are reached through unexported embedded structs. Their
+	// values can only be read through an addressable struct.
+	needsAddr bool
+}
+
+type fieldPlan struct {
+	// index is the index sequence of the field, longer than one for fields of embedded
+	// structs.
+	index []int
+	name  string
+	// key is the JSON encoded name including the colon.
+	key       string
+	mode      fieldMode
+	omitEmpty bool
+}
+
+var structPlans sync.Map // reflect.Type -> *structPlan, nil if the type has no log tags
+
+// structPlanFor returns the cached plan for a struct type, or nil if neither the type nor
+// any type reachable through its fields uses log tags.
+func structPlanFor(t reflect.Type) *structPlan {
+	if cached, ok := structPlans.Load(t); ok {
+		return cached.(*structPlan)
+	}
+
+	plan := buildStructPlan(t)
+	cached, _ := structPlans.LoadOrStore(t, plan)
+	return cached.(*structPlan)
+}
+
+// buildStructPlan selects the fields like json.Marshal: fields of embedded structs without
+// a name are promoted, and of several fields with the same name the least nested one wins,
+// or the one with a name in its tag. Fields that stay ambiguous are omitted.
+func buildStructPlan(t reflect.Type) *structPlan {
+	if !usesLogTags(t) {
+		return nil
+	}
+
+	type embedded struct {
+		typ        reflect.Type
+		index      []int
+		unexported bool
+	}
+	type candidate struct {
+		fieldPlan
+		depth      int
+		named      bool
+		unexported bool
+	}
+
+	var candidates []candidate
+	visited := map[reflect.Type]bool{}
+	for current, depth := []embedded{{typ: t}}, 0; len(current) > 0; depth++ {
+		var next []embedded
+		for _, s := range current {
+			if visited[s.typ] {
+				continue
+			}
+			for i := 0; i < s.typ.NumField(); i++ {
+				f := s.typ.Field(i)
+				jsonName, jsonOpts, _ := strings.Cut(f.Tag.Get("json"), ",")
+				tag := f.Tag.Get(structTagName)
+				if jsonName == "-" || tag == "-" {
+					continue
+				}
+
+				name, mode := jsonName, fieldPlain
+				for _, opt := range strings.Split(tag, ",") {
+					switch {
+					case opt == "mask":
+						mode = fieldMask
+					case opt == "hash":
+						mode = fieldHash
+					case strings.HasPrefix(opt, "name="):
+						name = strings.TrimPrefix(opt, "name=")
+					}
+				}
+
+				index := append(append([]int(nil), s.index...), i)
+				if typ, ok := embeddedStruct(f); ok && name == "" {
+					next = append(next, embedded{typ: typ, index: index, unexported: s.unexported || f.PkgPath != ""})
+					continue
+				}
+				if f.PkgPath != "" {
+					// unexported
+					continue
+				}
+
+				named := name != ""
+				if !named {
+					name = f.Name
+				}
+				candidates = append(candidates, candidate{
+					fieldPlan: fieldPlan{
+						index:     index,
+						name:      name,
+						key:       jsonKey(name),
+						mode:      mode,
+						omitEmpty: hasOption(jsonOpts, "omitempty"),
+					},
+					depth:      depth,
+					named:      named,
+					unexported: s.unexported,
+				})
+			}
+		}
+		for _, s := range current {
+			visited[s.typ] = true
+		}
+		current = next
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.name != b.name {
+			return a.name < b.name
+		}
+		if a.depth != b.depth {
+			return a.depth < b.depth
+		}
+		return a.named && !b.named
+	})
+
+	plan := &structPlan{}
+	for i := 0; i < len(candidates); {
+		first := candidates[i]
+		j := i + 1
+		for j < len(candidates) && candidates[j].name == first.name {
+			j++
+		}
+		dominant := len(candidates[i:j]) == 1 || candidates[i+1].depth > first.depth ||
+			first.named && !candidates[i+1].named
+		if dominant {
+			plan.fields = append(plan.fields, first.fieldPlan)
+			plan.needsAddr = plan.needsAddr || first.unexported
+		}
+		i = j
+	}
+	sort.Slice(plan.fields, func(i, j int) bool {
+		a, b := plan.fields[i].index, plan.fields[j].index
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+
+	return plan
+}
+
+// embeddedStruct returns the struct type of an embedded field, which json.Marshal flattens
+// unless the field is named by its tag.
+func embeddedStruct(f reflect.StructField) (reflect.Type, bool) {
+	if !f.Anonymous {
+		return nil, false
+	}
+	t := f.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t, t.Kind() == reflect.Struct
+}
+
+// hasOption returns true if the comma separated options contain option.
+func hasOption(options, option string) bool {
+	for options != "" {
+		var opt string
+		opt, options, _ = strings.Cut(options, ",")
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+var logTagTypes sync.Map // reflect.Type -> bool
+
+// usesLogTags returns true if t is a struct with log tags, or log tags are reachable
+// through the fields, elements or pointers of t. Types that encode themselves, e.g. by
+// implementing json.Marshaler, are not inspected.
+func usesLogTags(t reflect.Type) bool {
+	if cached, ok := logTagTypes.Load(t); ok {
+		return cached.(bool)
+	}
+	uses := findLogTags(t, map[reflect.Type]bool{})
+	logTagTypes.Store(t, uses)
+	return uses
+}
+
+// findLogTags implements usesLogTags. Recursive types are only inspected once, visiting
+// contains the structs that are being inspected.
+func findLogTags(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if encodesItself(t) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return findLogTags(t.Elem(), visiting)
+	case reflect.Struct:
+		if visiting[t] {
+			return false
+		}
+		visiting[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if _, ok := embeddedStruct(f); f.PkgPath != "" && !ok {
+				continue
+			}
+			if jsonName, _, _ := strings.Cut(f.Tag.Get("json"), ","); jsonName == "-" {
+				continue
+			}
+			if _, ok := f.Tag.Lookup(structTagName); ok || findLogTags(f.Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var selfEncodingTypesCache sync.Map // reflect.Type -> bool
+
+// encodesItself returns true if values of t are encoded by one of their methods, see
+// encodeUserValue.
+func encodesItself(t reflect.Type) bool {
+	if cached, ok := selfEncodingTypesCache.Load(t); ok {
+		return cached.(bool)
+	}
+	encodes := false
+	for _, i := range selfEncodingTypes {
+		if t.Implements(i) {
+			encodes = true
+			break
+		}
+	}
+	selfEncodingTypesCache.Store(t, encodes)
+	return encodes
+}
+
+var selfEncodingTypes = []reflect.Type{
+	reflect.TypeOf((*LazyValue)(nil)).Elem(),
+	reflect.TypeOf((*JSONValueWriter)(nil)).Elem(),
+	reflect.TypeOf((*json.Marshaler)(nil)).Elem(),
+	reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem(),
+	reflect.TypeOf((*error)(nil)).Elem(),
+	stringerType,
+}
+
+// jsonKey returns name as JSON string followed by a colon.
+func jsonKey(name string) string {
+	var sb strings.Builder
+	sw := MakeStackWriter(&sb)
+	sw.WriteJSONString(name)
+	sw.Write(":")
+	sw.Flush()
+	return sb.String()
+}
+
+// reflectState tracks the pointers, maps and slices that are being encoded, to stop at cycles
+// like encoding/json does.
+type reflectState struct {
+	seen map[reflectRef]struct{}
+}
+
+type reflectRef struct {
+	typ reflect.Type
+	ptr uintptr
+	len int
+}
+
+// enter returns false if v is already being encoded, otherwise v is tracked until leave.
+func (s *reflectState) enter(v reflect.Value) bool {
+	ref := reflectRef{typ: v.Type(), ptr: v.Pointer()}
+	if v.Kind() == reflect.Slice {
+		ref.len = v.Len()
+	}
+	if _, ok := s.seen[ref]; ok {
+		return false
+	}
+	if s.seen == nil {
+		s.seen = map[reflectRef]struct{}{}
+	}
+	s.seen[ref] = struct{}{}
+	return true
+}
+
+func (s *reflectState) leave(v reflect.Value) {
+	ref := reflectRef{typ: v.Type(), ptr: v.Pointer()}
+	if v.Kind() == reflect.Slice {
+		ref.len = v.Len()
+	}
+	delete(s.seen, ref)
+}
+
+// encodeStruct writes a struct that uses log tags directly, without json.Marshal.
+func (e *encoder) encodeStruct(sw *StackWriter, plan *structPlan, v reflect.Value, state *reflectState) (n int, err error) {
+	nw, err := sw.Write("{")
+	n += nw
+	if err != nil {
+		return n, err
+	}
+
+	if plan.needsAddr && !v.CanAddr() {
+		addressable := reflect.New(v.Type()).Elem()
+		addressable.Set(v)
+		v = addressable
+	}
+
+	first := true
+	for _, f := range plan.fields {
+		fv, ok := fieldByIndex(v, f.index)
+		if !ok || f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		if !first {
+			nw, err = sw.Write(",")
+			n += nw
+			if err != nil {
+				return n, err
+			}
+		}
+		first = false
+		nw, err = sw.Write(f.key)
+		n += nw
+		if err != nil {
+			return n, err
+		}
+
+		switch {
+		case e.redactor != nil && e.redactor.matchesKey(f.name):
+			nw, err = e.redactor.writeRedacted(sw, fv.Interface())
+		case f.mode == fieldMask:
+			nw, err = sw.WriteJSONString(maskValue(fv))
+		case f.mode == fieldHash:
+			nw, err = sw.WriteJSONString(e.hashValue(fv))
+		default:
+			nw, err = e.encodeReflectValue(sw, fv, state)
+		}
+		n += nw
+		if err != nil {
+			return n, err
+		}
+	}
+
+	nw, err = sw.Write("}")
+	n += nw
+	return n, err
+}
+
+// fieldByIndex returns the field of v at the index sequence, or false if it belongs to an
+// embedded struct behind a nil pointer. Fields of unexported embedded structs are made
+// readable, like json.Marshal does, if v is addressable.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+		if !v.CanInterface() && v.CanAddr() {
+			v = reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+		}
+	}
+	return v, true
+}
+
+// isEmptyValue returns true for the values that the json option omitempty omits.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// encodeReflectValue writes primitive kinds without boxing them into an interface. Values
+// with log tags are walked with the same state, so cycles are found. Types that encode
+// themselves are passed to encodeValue, so the precedence of their methods is the same as
+// for top-level values.
+func (e *encoder) encodeReflectValue(sw *StackWriter, v reflect.Value, state *reflectState) (n int, err error) {
+	if !encodesItself(v.Type()) {
+		switch v.Kind() {
+		case reflect.String:
+			if v.Type() == reflect.TypeOf("") {
+				return e.encodeValue(sw, v.String())
+			}
+		case reflect.Bool:
+			return sw.Write(strconv.FormatBool(v.Bool()))
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return sw.Write(strconv.FormatInt(v.Int(), 10))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return sw.Write(strconv.FormatUint(v.Uint(), 10))
+		case reflect.Float32:
+			return writeFloat(sw, v.Float(), 32)
+		case reflect.Float64:
+			return writeFloat(sw, v.Float(), 64)
+		case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+			if v.IsNil() {
+				return sw.Write("null")
+			}
+		}
+		if v.Kind() == reflect.Interface && usesLogTags(v.Elem().Type()) {
+			return e.encodeTagged(sw, v.Elem(), state)
+		}
+		if usesLogTags(v.Type()) {
+			return e.encodeTagged(sw, v, state)
+		}
+	}
+	if !v.CanInterface() {
+		return sw.Write("null")
+	}
+	return e.encodeValue(sw, v.Interface())
+}
+
+// encodeReflect encodes values that use log tags, other values are passed to json.Marshal.
+func (e *encoder) encodeReflect(sw *StackWriter, value interface{}) (n int, ok bool, err error) {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || !usesLogTags(v.Type()) {
+		return 0, false, nil
+	}
+	n, err = e.encodeTagged(sw, v, &reflectState{})
+	return n, true, err
+}
+
+// encodeTagged walks pointers, slices, arrays and maps down to the structs with log tags.
+// A pointer, slice or map that contains itself is written as "<ERROR: cycle>".
+func (e *encoder) encodeTagged(sw *StackWriter, v reflect.Value, state *reflectState) (n int, err error) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		if v.IsNil() {
+			return sw.Write("null")
+		}
+		if !state.enter(v) {
+			return sw.WriteJSONString("<ERROR: cycle>")
+		}
+		defer state.leave(v)
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		return e.encodeReflectValue(sw, v.Elem(), state)
+	case reflect.Struct:
+		return e.encodeStruct(sw, structPlanFor(v.Type()), v, state)
+	case reflect.Slice, reflect.Array:
+		return e.encodeElements(sw, v, state)
+	case reflect.Map:
+		return e.encodeMap(sw, v, state)
+	}
+	return e.encodeReflectValue(sw, v, state)
+}
+
+func (e *encoder) encodeElements(sw *StackWriter, v reflect.Value, state *reflectState) (n int, err error) {
+	nw, err := sw.Write("[")
+	n += nw
+	if err != nil {
+		return n, err
+	}
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			nw, err = sw.Write(",")
+			n += nw
+			if err != nil {
+				return n, err
+			}
+		}
+		nw, err = e.encodeReflectValue(sw, v.Index(i), state)
+		n += nw
+		if err != nil {
+			return n, err
+		}
+	}
+	nw, err = sw.Write("]")
+	n += nw
+	return n, err
+}
+
+// encodeMap writes the entries of a map sorted by key, like json.Marshal. Keys of the
+// redactor are redacted.
+func (e *encoder) encodeMap(sw *StackWriter, v reflect.Value, state *reflectState) (n int, err error) {
+	type entry struct {
+		key   string
+		value reflect.Value
+	}
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := mapKey(iter.Key())
+		if err != nil {
+			return 0, err
+		}
+		entries = append(entries, entry{key: key, value: iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+
+	nw, err := sw.Write("{")
+	n += nw
+	if err != nil {
+		return n, err
+	}
+	for i, entry := range entries {
+		if i > 0 {
+			nw, err = sw.Write(",")
+			n += nw
+			if err != nil {
+				return n, err
+			}
+		}
+		nw, err = sw.WriteJSONString(entry.key)
+		n += nw
+		if err != nil {
+			return n, err
+		}
+		nw, err = sw.Write(":")
+		n += nw
+		if err != nil {
+			return n, err
+		}
+		if e.redactor != nil && e.redactor.matchesKey(entry.key) {
+			nw, err = e.redactor.writeRedacted(sw, entry.value.Interface())
+		} else {
+			nw, err = e.encodeReflectValue(sw, entry.value, state)
+		}
+		n += nw
+		if err != nil {
+			return n, err
+		}
+	}
+	nw, err = sw.Write("}")
+	n += nw
+	return n, err
+}
+
+// mapKey returns a map key as string, following the rules of json.Marshal.
+func mapKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if k.Kind() == reflect.Ptr && k.IsNil() {
+			return "", nil
+		}
+		text, err := tm.MarshalText()
+		return string(text), err
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("unsupported map key type %s", k.Type())
+}
+
+// maskValue replaces all but the last 4 characters of a string with '*'. Strings of up to 8
+// characters are replaced completely, other values are written as "****".
+func maskValue(v reflect.Value) string {
+	if v.Kind() != reflect.String {
+		return "****"
+	}
+	runes := []rune(v.String())
+	keep := 4
+	if len(runes) <= 2*keep {
+		keep = 0
+	}
+	for i := 0; i < len(runes)-keep; i++ {
+		runes[i] = '*'
+	}
+	return string(runes)
+}
+
+func (e *encoder) hashValue(v reflect.Value) string {
+	var s string
+	if v.Kind() == reflect.String {
+		s = v.String()
+	} else {
+		s = formatReflectValue(v)
+	}
+	if e.redactor != nil && len(e.redactor.hmacKey) > 0 {
+		return e.redactor.replacement(s)
+	}
+	sum := sha256.Sum256([]byte(s))
+	return "[SHA256:" + hex.EncodeToString(sum[:16]) + "]"
+}
+
+func formatReflectValue(v reflect.Value) string {
+	var sb strings.Builder
+	sw := MakeStackWriter(&sb)
+	defaultEncoder.encodeReflectValue(&sw, v, &reflectState{})
+	sw.Flush()
+	return sb.String()
+}
+
+var stringerType = reflect.TypeOf((*interface{ String() string })(nil)).Elem()
@@ -0,0 +1,243 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testCustomer struct {
+	ID       string `log:"name=customer_id"`
+	Name     string
+	Password string `log:"-"`
+	IBAN     string `log:"mask"`
+	Email    string `log:"hash"`
+	Age      int    `json:"age"`
+	Address  *testAddress
+	secret   string
+}
+
+type testAddress struct {
+	Street string `log:"mask"`
+	City   string
+}
+
+type testOrder struct {
+	ID       string
+	Customer testCustomer
+	Shipping []*testAddress `json:"shipping"`
+}
+
+type testKind int
+
+func (testKind) MarshalText() ([]byte, error) {
+	return []byte("kind-text"), nil
+}
+
+type testTypedFields struct {
+	Kind     testKind `log:"name=kind"`
+	Duration time.Duration
+	Failing  testFailingMarshaler
+}
+
+type testNode struct {
+	Name     string `log:"mask"`
+	Children []testNode
+}
+
+type testLinked struct {
+	Name  string      `log:"name=name"`
+	Next  *testLinked `log:"name=next"`
+	Value interface{}
+}
+
+type testAudit struct {
+	By     string `log:"mask"`
+	At     int    `json:"at,omitempty"`
+	Source string
+}
+
+type testReview struct {
+	Source string
+	Score  int
+}
+
+type testEmbedding struct {
+	testAudit
+	*testAddress
+	testReview
+	Name string `json:"name,omitempty"`
+	City string
+	Note string   `json:",omitempty"`
+	Tags []string `json:"tags,omitempty"`
+}
+
+func Test_encodeValue_StructTags(t *testing.T) {
+	self := &testLinked{Name: "self"}
+	self.Next = self
+	first := &testLinked{Name: "first", Next: &testLinked{Name: "second"}}
+	first.Next.Next = first
+	viaInterface := &testLinked{Name: "interface"}
+	viaInterface.Value = viaInterface
+	shared := &testLinked{Name: "shared"}
+
+	tests := []struct {
+		name       string
+		value      interface{}
+		redactor   *Redactor
+		wantString string
+	}{{
+		name: "Encode tagged struct",
+		value: testCustomer{
+			ID:       "c-1",
+			Name:     "Jane",
+			Password: "pw",
+			IBAN:     "DE89370400440532013000",
+			Email:    "jane@example.com",
+			Age:      42,
+			secret:   "hidden",
+		},
+		wantString: `{"customer_id":"c-1","Name":"Jane","IBAN":"******************3000","Email":"[SHA256:` +
+			testSHA256("jane@example.com") + `]","age":42,"Address":null}`,
+	}, {
+		name: "Encode pointer to tagged struct with nested struct",
+		value: &testCustomer{
+			ID:      "c-2",
+			IBAN:    "short",
+			Address: &testAddress{Street: "Main Street 1", City: "Berlin"},
+		},
+		wantString: `{"customer_id":"c-2","Name":"","IBAN":"*****","Email":"[SHA256:` +
+			testSHA256("") + `]","age":0,"Address":{"Street":"*********et 1","City":"Berlin"}}`,
+	}, {
+		name:       "Hash with HMAC of redactor",
+		value:      testAddress{Street: "x", City: "Berlin"},
+		redactor:   NewRedactor(RedactorConfig{Keys: []string{"city"}, HMACKey: []byte("k")}),
+		wantString: `{"Street":"*","City":"[HMAC:` + testHMAC("k", "Berlin") + `]"}`,
+	}, {
+		name:       "Encode struct without tags with json.Marshal",
+		value:      testUnknownType{A: 1, B: "b"},
+		wantString: `{"A":1,"B":"b"}`,
+	}, {
+		name:  "Encode tagged struct nested in untagged struct",
+		value: testOrder{ID: "o-1", Customer: testCustomer{Password: "hunter2"}, Shipping: []*testAddress{{Street: "x", City: "Berlin"}, nil}},
+		wantString: `{"ID":"o-1","Customer":{"customer_id":"","Name":"","IBAN":"","Email":"[SHA256:` +
+			testSHA256("") + `]","age":0,"Address":null},"shipping":[{"Street":"*","City":"Berlin"},null]}`,
+	}, {
+		name:       "Encode slice of tagged structs",
+		value:      []testCustomer{{Name: "Jane", Password: "hunter2"}},
+		wantString: `[{"customer_id":"","Name":"Jane","IBAN":"","Email":"[SHA256:` + testSHA256("") + `]","age":0,"Address":null}]`,
+	}, {
+		name:       "Encode array of pointers to tagged structs",
+		value:      [2]*testAddress{{Street: "Main Street 1", City: "Berlin"}},
+		wantString: `[{"Street":"*********et 1","City":"Berlin"},null]`,
+	}, {
+		name:       "Mask strings of up to 8 characters completely",
+		value:      []testAddress{{Street: "Street 8"}, {Street: "Street 09"}, {Street: "Straße 9"}},
+		wantString: `[{"Street":"********","City":""},{"Street":"*****t 09","City":""},{"Street":"********","City":""}]`,
+	}, {
+		name:       "Encode map of tagged structs sorted by key",
+		value:      map[string]testAddress{"b": {City: "Bonn"}, "a": {City: "Aachen"}},
+		wantString: `{"a":{"Street":"","City":"Aachen"},"b":{"Street":"","City":"Bonn"}}`,
+	}, {
+		name:       "Encode map with integer keys",
+		value:      map[int][]testAddress{2: nil, 1: {{City: "Berlin"}}},
+		wantString: `{"1":[{"Street":"","City":"Berlin"}],"2":null}`,
+	}, {
+		name:       "Redact keys of maps with tagged structs",
+		value:      map[string]*testAddress{"secret": {City: "Berlin"}},
+		redactor:   NewRedactor(RedactorConfig{Keys: []string{"secret"}}),
+		wantString: `{"secret":"[REDACTED]"}`,
+	}, {
+		name:       "Encode recursive type",
+		value:      testNode{Name: "root-node", Children: []testNode{{Name: "child-node"}}},
+		wantString: `{"Name":"*****node","Children":[{"Name":"******node","Children":null}]}`,
+	}, {
+		name:       "Methods of fields take precedence over their kind",
+		value:      testTypedFields{Kind: 1, Duration: time.Second},
+		wantString: `{"kind":"kind-text","Duration":"1s","Failing":"<ERROR: MarshalJSON failed>"}`,
+	}, {
+		name:       "Encode slice without tagged structs with json.Marshal",
+		value:      []testUnknownType{{A: 1, B: "b"}},
+		wantString: `[{"A":1,"B":"b"}]`,
+	}, {
+		name:       "Flatten embedded structs and omit empty fields like json.Marshal",
+		value:      testEmbedding{testAudit: testAudit{By: "administrator", Source: "a"}, testReview: testReview{Source: "b"}, City: "Bonn"},
+		wantString: `{"By":"*********ator","Score":0,"City":"Bonn"}`,
+	}, {
+		name: "Flatten embedded pointer to struct",
+		value: &testEmbedding{
+			testAudit:   testAudit{By: "administrator", At: 5},
+			testAddress: &testAddress{Street: "Main Street 1", City: "Berlin"},
+			testReview:  testReview{Score: 3},
+			Name:        "n",
+			City:        "Bonn",
+			Note:        "x",
+			Tags:        []string{"a"},
+		},
+		wantString: `{"By":"*********ator","at":5,"Street":"*********et 1","Score":3,"name":"n","City":"Bonn","Note":"x","tags":["a"]}`,
+	}, {
+		name:       "Stop at pointer to itself",
+		value:      self,
+		wantString: `{"name":"self","next":"<ERROR: cycle>","Value":null}`,
+	}, {
+		name:       "Stop at cycle of two pointers",
+		value:      first,
+		wantString: `{"name":"first","next":{"name":"second","next":"<ERROR: cycle>","Value":null},"Value":null}`,
+	}, {
+		name:       "Stop at cycle through interface",
+		value:      viaInterface,
+		wantString: `{"name":"interface","next":null,"Value":"<ERROR: cycle>"}`,
+	}, {
+		name:       "Encode pointers that are used twice without cycle",
+		value:      []*testLinked{shared, shared},
+		wantString: `[{"name":"shared","next":null,"Value":null},{"name":"shared","next":null,"Value":null}]`,
+	}, {
+		name:       "Encode nil pointer to tagged struct",
+		value:      (*testAddress)(nil),
+		wantString: `null`,
+	},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := bytes.NewBufferString("")
+			sw := MakeStackWriter(out)
+			enc := encoder{redactor: tt.redactor}
+
+			bytesWritten, err := enc.encodeValue(&sw, tt.value)
+			if err != nil {
+				t.Fatalf("encodeValue() error = %v", err)
+			}
+
+			sw.Flush()
+			outString := out.String()
+			if tt.wantString != outString {
+				t.Errorf("Written string does not match.\nWant: %s\nGot : %s", tt.wantString, outString)
+			}
+			if bytesWritten != len(outString) {
+				t.Errorf("encodeValue() returned %d bytes, %d bytes were written", bytesWritten, len(outString))
+			}
+		})
+	}
+}
+
+func Test_structPlanFor_Cached(t *testing.T) {
+	v := testAddress{Street: "Main Street 1", City: "Berlin"}
+	sw := MakeStackWriter(bytes.NewBufferString(""))
+	defaultEncoder.encodeValue(&sw, v)
+
+	cached, ok := structPlans.Load(reflect.TypeOf(v))
+	if !ok || cached.(*structPlan) == nil {
+		t.Fatalf("No plan cached for %T", v)
+	}
+	if len(cached.(*structPlan).fields) != 2 {
+		t.Errorf("Plan has %d fields, want 2", len(cached.(*structPlan).fields))
+	}
+}
+
+func testSHA256(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:16])
+}
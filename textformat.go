@@ -0,0 +1,231 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Format selects how records are written.
+type Format uint8
+
+const (
+	// FormatJSON writes one JSON object per line. It is the default.
+	FormatJSON Format = iota
+	// FormatLogfmt writes key=value pairs, e.g. ts=2022-02-01T13:01:02.123456Z level=INFO
+	// message="Order paid" order=42. Strings are quoted if they contain spaces, quotes, = or
+	// control characters, objects and arrays are written as quoted JSON.
+	FormatLogfmt
+	// FormatConsole writes lines for humans, e.g. during development:
+	// 2022-02-01T13:01:02.123456Z INFO  payment: Order paid order=42. The fields are written
+	// like logfmt, stack traces on the following lines.
+	FormatConsole
+)
+
+// ParseFormat returns the format of the given name: json, logfmt or console.
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "", "json":
+		return FormatJSON, nil
+	case "logfmt":
+		return FormatLogfmt, nil
+	case "console":
+		return FormatConsole, nil
+	}
+	return FormatJSON, fmt.Errorf("invalid log format %q, expected json, logfmt or console", name)
+}
+
+// WithFormat sets the format of the records, default is FormatJSON. Logfmt and console
+// records are encoded as JSON first and converted, so they are slower and allocate.
+func WithFormat(format Format) Option {
+	return func(l *instance) {
+		l.format = format
+	}
+}
+
+// formatWriter converts the JSON records written by the logger to logfmt or console lines.
+// A record may be written in several chunks, so the chunks are collected until the newline
+// that ends every record. It is only used while the logger is locked.
+type formatWriter struct {
+	writer  io.Writer
+	format  Format
+	schema  Schema
+	pending []byte
+}
+
+func newFormatWriter(writer io.Writer, format Format, s *schema) *formatWriter {
+	return &formatWriter{writer: writer, format: format, schema: s.source}
+}
+
+func (w *formatWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	var err error
+	done := 0
+	for err == nil {
+		end := bytes.IndexByte(w.pending[done:], '\n')
+		if end < 0 {
+			break
+		}
+		_, err = w.writer.Write(w.convert(w.pending[done : done+end]))
+		done += end + 1
+	}
+	w.pending = w.pending[:copy(w.pending, w.pending[done:])]
+	return len(p), err
+}
+
+func (w *formatWriter) sinks() []io.Writer {
+	return []io.Writer{w.writer}
+}
+
+// recordField is a key and the JSON encoded value of a record.
+type recordField struct {
+	key   string
+	value json.RawMessage
+}
+
+// convert returns the record in the format of the writer, including the newline. Lines that
+// are no JSON object are returned unchanged.
+func (w *formatWriter) convert(record []byte) []byte {
+	fields, ok := parseRecord(record)
+	if !ok {
+		return append(append([]byte(nil), record...), '\n')
+	}
+
+	var b bytes.Buffer
+	if w.format == FormatConsole {
+		w.writeConsole(&b, fields)
+	} else {
+		for _, f := range fields {
+			writeLogfmtField(&b, f)
+		}
+	}
+	b.WriteByte('\n')
+	return b.Bytes()
+}
+
+// writeConsole writes time, level, logger and message in front of the other fields, and the
+// stack trace on the following lines.
+func (w *formatWriter) writeConsole(b *bytes.Buffer, fields []recordField) {
+	var level, name, message, stackTrace string
+	var other []recordField
+	for _, f := range fields {
+		switch {
+		case f.key == "":
+			other = append(other, f)
+		case f.key == w.schema.TimeKey:
+			b.WriteString(plainText(f.value))
+			b.WriteByte(' ')
+		case f.key == w.schema.LevelKey:
+			level = plainText(f.value)
+		case f.key == w.schema.LoggerKey:
+			name = plainText(f.value)
+		case f.key == w.schema.MessageKey:
+			message = plainText(f.value)
+		case f.key == w.schema.StackTraceKey:
+			stackTrace = plainText(f.value)
+		default:
+			other = append(other, f)
+		}
+	}
+
+	b.WriteString(level)
+	for i := len(level); i < len("ERROR"); i++ {
+		b.WriteByte(' ')
+	}
+	b.WriteByte(' ')
+	if name != "" {
+		b.WriteString(name)
+		b.WriteString(": ")
+	}
+	b.WriteString(lineBreaks.Replace(message))
+	for _, f := range other {
+		writeLogfmtField(b, f)
+	}
+	if stackTrace != "" {
+		b.WriteByte('\n')
+		b.WriteString(stackTrace)
+	}
+}
+
+var lineBreaks = strings.NewReplacer("\n", "\\n", "\r", "\\r")
+
+// parseRecord returns the fields of a JSON object in their order.
+func parseRecord(record []byte) ([]recordField, bool) {
+	d := json.NewDecoder(bytes.NewReader(record))
+	d.UseNumber()
+	if t, err := d.Token(); err != nil || t != json.Delim('{') {
+		return nil, false
+	}
+	var fields []recordField
+	for d.More() {
+		t, err := d.Token()
+		if err != nil {
+			return nil, false
+		}
+		var f recordField
+		f.key, _ = t.(string)
+		if err := d.Decode(&f.value); err != nil {
+			return nil, false
+		}
+		fields = append(fields, f)
+	}
+	return fields, true
+}
+
+// writeLogfmtField writes " key=value", or "key=value" at the start of the line.
+func writeLogfmtField(b *bytes.Buffer, f recordField) {
+	if b.Len() > 0 {
+		b.WriteByte(' ')
+	}
+	b.WriteString(logfmtKey(f.key))
+	b.WriteByte('=')
+	b.WriteString(logfmtValue(f.value))
+}
+
+// logfmtKey replaces the characters that are not allowed in logfmt keys by underscores.
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// logfmtValue returns strings and nested values quoted if necessary, numbers, booleans and
+// null as they are.
+func logfmtValue(value json.RawMessage) string {
+	if len(value) > 0 && (value[0] == '"' || value[0] == '{' || value[0] == '[') {
+		s := plainText(value)
+		if s == "" || strings.ContainsAny(s, " =\"\\") || strings.IndexFunc(s, isControl) >= 0 {
+			return strconv.Quote(s)
+		}
+		return s
+	}
+	return string(value)
+}
+
+// plainText returns a JSON string as its text and other values as compact JSON.
+func plainText(value json.RawMessage) string {
+	if len(value) > 0 && value[0] == '"' {
+		var s string
+		if err := json.Unmarshal(value, &s); err == nil {
+			return s
+		}
+	}
+	var b bytes.Buffer
+	if err := json.Compact(&b, value); err != nil {
+		return string(value)
+	}
+	return b.String()
+}
+
+func isControl(r rune) bool {
+	return r < ' ' || r == 0x7f || r == '\u2028' || r == '\u2029'
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithFormat_Logfmt(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := NewWithWriter(LvlInfo, out, WithFormat(FormatLogfmt), WithClock(NewFakeClock(goldenTime)), WithFields(String("service", "payment")))
+
+	logger.Named("client").Info("Order paid",
+		"order", 42,
+		"amount", 9.5,
+		"paid", true,
+		"note", "two words",
+		"quote", `a"b`,
+		"empty", "",
+		"nil", nil,
+		"map", map[string]interface{}{"a": []int{1, 2}},
+		"key with=space", "x",
+		"newline", "a\nb",
+		"error", errors.New("failed"),
+	)
+
+	expected := `ts=2022-02-01T13:01:02.123456Z level=INFO message="Order paid" logger=client service=payment order=42 amount=9.500000 paid=true note="two words" quote="a\"b" empty="" nil=null map="{\"a\":[1,2]}" key_with_space=x newline="a\nb" error=failed` + "\n"
+	if out.String() != expected {
+		t.Errorf("Record is incorrect, Expected\n%s\nActual\n%s", expected, out)
+	}
+}
+
+func TestWithFormat_Console(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := NewWithWriter(LvlInfo, out, WithFormat(FormatConsole), WithClock(NewFakeClock(goldenTime)),
+		WithoutCaller(), WithStackTraceLevel(LevelError))
+
+	logger.Info("Started", "port", 8080)
+	logger.Named("payment").Warn("Slow\nresponse", "duration", 1500*time.Millisecond)
+	logger.Error("Failed")
+
+	lines := strings.Split(out.String(), "\n")
+	expected := []string{
+		`2022-02-01T13:01:02.123456Z INFO  Started port=8080`,
+		`2022-02-01T13:01:02.123456Z WARN  payment: Slow\nresponse duration=1.5s`,
+		`2022-02-01T13:01:02.123456Z ERROR Failed`,
+	}
+	for i, line := range expected {
+		if lines[i] != line {
+			t.Errorf("Line %d is incorrect, Expected\n%s\nActual\n%s", i, line, lines[i])
+		}
+	}
+	if !strings.HasPrefix(lines[3], "github.com/fond-of-vertigo/logger.TestWithFormat_Console") {
+		t.Errorf("Stack trace should follow the record, Actual\n%s", out)
+	}
+}
+
+func TestWithFormat_Schema(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := NewWithWriter(LvlInfo, out, WithFormat(FormatConsole), WithSchema(ECSSchema), WithTimeFormat(TimeNone), WithoutCaller())
+
+	logger.Named("payment").Info("Started", "port", 8080)
+
+	expected := "info  payment: Started port=8080\n"
+	if out.String() != expected {
+		t.Errorf("Record is incorrect, Expected\n%s\nActual\n%s", expected, out)
+	}
+}
+
+func TestWithFormat_LongRecord(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := NewWithWriter(LvlInfo, out, WithFormat(FormatLogfmt), WithTimeFormat(TimeNone))
+
+	long := makeString(3 * bufSize)
+	logger.Info("Long", "long", long)
+	logger.Info("Short")
+
+	expected := "level=INFO message=Long long=" + long + "\nlevel=INFO message=Short\n"
+	if out.String() != expected {
+		t.Errorf("Records are incorrect, Actual\n%s", out)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := map[string]Format{"": FormatJSON, "JSON": FormatJSON, "logfmt": FormatLogfmt, "Console": FormatConsole}
+	for name, expected := range tests {
+		if format, err := ParseFormat(name); err != nil || format != expected {
+			t.Errorf("ParseFormat(%q) = %v, %v, Expected %v", name, format, err, expected)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil || err.Error() != `invalid log format "xml", expected json, logfmt or console` {
+		t.Errorf("Error is incorrect: %v", err)
+	}
+}
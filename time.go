@@ -1,10 +1,51 @@
 package logger
 
-import "time"
+import (
+	"strconv"
+	"time"
+)
 
-// FormatLogTime formats the given time with RFC3339Nano format, always uses UTC time zone.
-// It is an optimized version of time.Format(time.RFC3339Nano), 4x faster.
-// The return value is a byte array to avoid heap allocation.
+// TimeFormat selects how the timestamp of a record is written.
+type TimeFormat uint8
+
+const (
+	// TimeRFC3339Micro writes RFC3339 strings with microseconds, e.g. "2022-02-01T13:01:02.123456Z".
+	TimeRFC3339Micro TimeFormat = iota
+	// TimeRFC3339Milli writes RFC3339 strings with milliseconds, e.g. "2022-02-01T13:01:02.123Z".
+	TimeRFC3339Milli
+	// TimeRFC3339Nano writes RFC3339 strings with nanoseconds, e.g. "2022-02-01T13:01:02.123456789Z".
+	TimeRFC3339Nano
+	// TimeUnix writes the seconds since the Unix epoch as number, e.g. 1643720462.
+	TimeUnix
+	// TimeUnixMilli writes the milliseconds since the Unix epoch as number, e.g. 1643720462123.
+	TimeUnixMilli
+	// TimeUnixNano writes the nanoseconds since the Unix epoch as number, e.g. 1643720462123456789.
+	TimeUnixNano
+	// TimeNone writes no timestamp, e.g. for journald, which adds its own.
+	TimeNone
+)
+
+// WithTimeFormat sets the format of the record timestamp. Default is TimeRFC3339Micro.
+// Values of type time.Time are not affected.
+func WithTimeFormat(format TimeFormat) Option {
+	return func(l *instance) {
+		l.timeFormat = format
+	}
+}
+
+// WithTimeLocation writes RFC3339 timestamps in the given location with its offset, e.g.
+// WithTimeLocation(time.Local). Default is UTC.
+func WithTimeLocation(loc *time.Location) Option {
+	return func(l *instance) {
+		l.timeLocation = loc
+	}
+}
+
+// FormatLogTime formats the given time in RFC3339 format with microseconds, always uses UTC
+// time zone. It is an optimized version of time.Format, 4x faster.
+// The return value is a byte array to avoid heap allocation. Years before 0 and after 9999
+// do not fit, they are clamped to 0000-01-01T00:00:00.000000Z and 9999-12-31T23:59:59.999999Z.
+// AppendLogTime writes them in full.
 func FormatLogTime(t time.Time) (ts [27]byte) {
 	zoneName, _ := t.Zone()
 	if zoneName != "UTC" {
@@ -12,6 +53,11 @@ func FormatLogTime(t time.Time) (ts [27]byte) {
 	}
 
 	y, m, d := t.Date()
+	if y < 0 {
+		t, y, m, d = time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC), 0, 1, 1
+	} else if y > 9999 {
+		t, y, m, d = time.Date(9999, 12, 31, 23, 59, 59, 999999999, time.UTC), 9999, 12, 31
+	}
 	copy(ts[0:2], digits[y%10000/100][:])
 	copy(ts[2:4], digits[y%100][:])
 	ts[4] = '-'
@@ -37,6 +83,112 @@ func FormatLogTime(t time.Time) (ts [27]byte) {
 	return ts
 }
 
+// AppendLogTime appends t in the given format to dst, as JSON string or number. RFC3339
+// formats use loc, or UTC if loc is nil. It does not allocate if dst has a capacity of at
+// least 37 bytes.
+func AppendLogTime(dst []byte, t time.Time, format TimeFormat, loc *time.Location) []byte {
+	switch format {
+	case TimeRFC3339Milli:
+		return appendQuotedRFC3339(dst, t, 3, loc)
+	case TimeRFC3339Nano:
+		return appendQuotedRFC3339(dst, t, 9, loc)
+	case TimeUnix:
+		return strconv.AppendInt(dst, t.Unix(), 10)
+	case TimeUnixMilli:
+		return strconv.AppendInt(dst, t.UnixMilli(), 10)
+	case TimeUnixNano:
+		return strconv.AppendInt(dst, t.UnixNano(), 10)
+	case TimeNone:
+		return dst
+	default:
+		return appendQuotedRFC3339(dst, t, 6, loc)
+	}
+}
+
+func appendQuotedRFC3339(dst []byte, t time.Time, fracDigits int, loc *time.Location) []byte {
+	dst = append(dst, '"')
+	dst = appendRFC3339(dst, t, fracDigits, loc)
+	return append(dst, '"')
+}
+
+// appendRFC3339 appends t with a fixed number of fractional digits, which must be 3, 6 or 9.
+// Years before 0 and after 9999 are appended by time.Time.AppendFormat with RFC3339Nano.
+func appendRFC3339(dst []byte, t time.Time, fracDigits int, loc *time.Location) []byte {
+	if loc != nil {
+		t = t.In(loc)
+	} else if zoneName, _ := t.Zone(); zoneName != "UTC" {
+		t = t.UTC()
+	}
+	if y := t.Year(); y < 0 || y > 9999 {
+		return t.AppendFormat(dst, time.RFC3339Nano)
+	}
+	if fracDigits == 6 && loc == nil {
+		ts := FormatLogTime(t)
+		return append(dst, ts[:]...)
+	}
+
+	var ts [35]byte
+	y, m, d := t.Date()
+	copy(ts[0:2], digits[y%10000/100][:])
+	copy(ts[2:4], digits[y%100][:])
+	ts[4] = '-'
+	copy(ts[5:7], digits[m][:])
+	ts[7] = '-'
+	copy(ts[8:10], digits[d][:])
+	ts[10] = 'T'
+
+	h, min, s := t.Clock()
+	copy(ts[11:13], digits[h][:])
+	ts[13] = ':'
+	copy(ts[14:16], digits[min][:])
+	ts[16] = ':'
+	copy(ts[17:19], digits[s][:])
+	ts[19] = '.'
+
+	n := t.Nanosecond()
+	i := 20
+	switch fracDigits {
+	case 3:
+		n /= 1000000
+		ts[20] = byte('0' + n/100)
+		copy(ts[21:23], digits[n%100][:])
+		i = 23
+	case 9:
+		ts[20] = byte('0' + n/100000000)
+		copy(ts[21:23], digits[n%100000000/1000000][:])
+		copy(ts[23:25], digits[n%1000000/10000][:])
+		copy(ts[25:27], digits[n%10000/100][:])
+		copy(ts[27:29], digits[n%100][:])
+		i = 29
+	default:
+		n /= 1000
+		copy(ts[20:22], digits[n/10000][:])
+		copy(ts[22:24], digits[n%10000/100][:])
+		copy(ts[24:26], digits[n%100][:])
+		i = 26
+	}
+
+	offset := 0
+	if loc != nil {
+		_, offset = t.Zone()
+	}
+	if offset == 0 {
+		ts[i] = 'Z'
+		return append(dst, ts[:i+1]...)
+	}
+
+	ts[i] = '+'
+	if offset < 0 {
+		ts[i] = '-'
+		offset = -offset
+	}
+	offset /= 60
+	copy(ts[i+1:i+3], digits[offset/60][:])
+	ts[i+3] = ':'
+	copy(ts[i+4:i+6], digits[offset%60][:])
+	return append(dst, ts[:i+6]...)
+}
+
 var digits = [][2]byte{
 	{'0', '0'}, {'0', '1'}, {'0', '2'}, {'0', '3'}, {'0', '4'}, {'0', '5'},
 	{'0', '6'}, {'0', '7'}, {'0', '8'}, {'0', '9'}, {'1', '0'}, {'1', '1'},
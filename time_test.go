@@ -1,6 +1,8 @@
 package logger
 
 import (
+	"bytes"
+	"regexp"
 	"testing"
 	"time"
 )
@@ -48,3 +50,106 @@ func TestFormatLogTime_ZeroAlloc(t *testing.T) {
 		t.Errorf("Allocs detected! Want 0 allocs, got %f", allocs)
 	}
 }
+
+func TestAppendLogTime(t *testing.T) {
+	ts := time.Date(2022, 2, 1, 13, 1, 2, 123456789, time.UTC)
+	berlin := time.FixedZone("CET", 3600)
+	newYork := time.FixedZone("EST", -5*3600-30*60)
+
+	tests := []struct {
+		format   TimeFormat
+		loc      *time.Location
+		expected string
+	}{
+		{TimeRFC3339Micro, nil, `"2022-02-01T13:01:02.123456Z"`},
+		{TimeRFC3339Milli, nil, `"2022-02-01T13:01:02.123Z"`},
+		{TimeRFC3339Nano, nil, `"2022-02-01T13:01:02.123456789Z"`},
+		{TimeRFC3339Milli, berlin, `"2022-02-01T14:01:02.123+01:00"`},
+		{TimeRFC3339Micro, newYork, `"2022-02-01T07:31:02.123456-05:30"`},
+		{TimeRFC3339Nano, time.UTC, `"2022-02-01T13:01:02.123456789Z"`},
+		{TimeUnix, berlin, `1643720462`},
+		{TimeUnixMilli, nil, `1643720462123`},
+		{TimeUnixNano, nil, `1643720462123456789`},
+		{TimeNone, nil, ``},
+	}
+	for _, tt := range tests {
+		actual := string(AppendLogTime(nil, ts.In(berlin), tt.format, tt.loc))
+		if actual != tt.expected {
+			t.Errorf("AppendLogTime(%d, %v) = %s, want %s", tt.format, tt.loc, actual, tt.expected)
+		}
+	}
+
+	// Fractions with leading zeros
+	ts = time.Date(2022, 2, 1, 13, 1, 2, 1002003, time.UTC)
+	if actual := string(AppendLogTime(nil, ts, TimeRFC3339Nano, nil)); actual != `"2022-02-01T13:01:02.001002003Z"` {
+		t.Errorf("AppendLogTime() = %s", actual)
+	}
+}
+
+func TestAppendLogTime_ZeroAlloc(t *testing.T) {
+	now := time.Now()
+	for format := TimeRFC3339Micro; format <= TimeNone; format++ {
+		allocs := testing.AllocsPerRun(10, func() {
+			var buf [40]byte
+			AppendLogTime(buf[:0], now, format, time.Local)
+		})
+		if allocs > 0.0 {
+			t.Errorf("Allocs detected for format %d! Want 0 allocs, got %f", format, allocs)
+		}
+	}
+}
+
+func TestLogger_TimeFormat(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := NewWithWriter(LvlInfo, out, WithTimeFormat(TimeUnixMilli))
+	logger.Info("msg")
+	if !regexp.MustCompile(`^\{"ts": \d{13}, "level": "INFO", "message": "msg"\}\n$`).MatchString(out.String()) {
+		t.Errorf("Record is incorrect: %s", out.String())
+	}
+
+	out.Reset()
+	logger = NewWithWriter(LvlInfo, out, WithTimeFormat(TimeNone), WithSchema(ECSSchema))
+	logger.Info("msg")
+	if out.String() != `{"log.level": "info", "message": "msg"}`+"\n" {
+		t.Errorf("Record is incorrect: %s", out.String())
+	}
+}
+
+func TestAppendLogTime_YearOutOfRange(t *testing.T) {
+	tests := map[string]struct {
+		time     time.Time
+		expected string
+	}{
+		"negative year":  {time.Date(-5, 3, 4, 5, 6, 7, 8, time.UTC), `"-0005-03-04T05:06:07.000000008Z"`},
+		"year 10000":     {time.Date(10000, 1, 2, 3, 4, 5, 0, time.UTC), `"10000-01-02T03:04:05Z"`},
+		"far future":     {time.Unix(1e18, 0), `"31688740476-10-23T01:46:40Z"`},
+		"zero time":      {time.Time{}, `"0001-01-01T00:00:00.000000Z"`},
+		"year 9999":      {time.Date(9999, 12, 31, 23, 59, 59, 999999999, time.UTC), `"9999-12-31T23:59:59.999999Z"`},
+		"offset to 9999": {time.Date(9999, 12, 31, 23, 30, 0, 0, time.FixedZone("X", -3600)), `"10000-01-01T00:30:00Z"`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if actual := string(AppendLogTime(nil, tt.time, TimeRFC3339Micro, nil)); actual != tt.expected {
+				t.Errorf("AppendLogTime() = %s, want %s", actual, tt.expected)
+			}
+
+			out := &bytes.Buffer{}
+			sw := MakeStackWriter(out)
+			var enc encoder
+			enc.encodeValue(&sw, tt.time)
+			sw.Flush()
+			if out.String() != tt.expected {
+				t.Errorf("encodeValue() = %s, want %s", out, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatLogTime_YearOutOfRange(t *testing.T) {
+	if ts := FormatLogTime(time.Date(-5, 3, 4, 5, 6, 7, 8, time.UTC)); string(ts[:]) != "0000-01-01T00:00:00.000000Z" {
+		t.Errorf("FormatLogTime() = %s", ts)
+	}
+	if ts := FormatLogTime(time.Unix(1e18, 0)); string(ts[:]) != "9999-12-31T23:59:59.999999Z" {
+		t.Errorf("FormatLogTime() = %s", ts)
+	}
+}
@@ -0,0 +1,106 @@
+package logger
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// DurationFormat selects how time.Duration values are written.
+type DurationFormat uint8
+
+const (
+	// DurationString writes durations as string, e.g. "1.5s".
+	DurationString DurationFormat = iota
+	// DurationMillis writes durations as floating point number of milliseconds, e.g. 1500.
+	DurationMillis
+	// DurationNanos writes durations as integer number of nanoseconds, e.g. 1500000000.
+	DurationNanos
+)
+
+// BytesFormat selects how []byte values are written.
+type BytesFormat uint8
+
+const (
+	// BytesBase64 writes byte slices as standard base64 string, like json.Marshal does.
+	BytesBase64 BytesFormat = iota
+	// BytesHex writes byte slices as hex string.
+	BytesHex
+	// BytesString writes byte slices as escaped UTF-8 string.
+	BytesString
+)
+
+// WithDurationFormat sets the format of time.Duration values. Default is DurationString.
+func WithDurationFormat(format DurationFormat) Option {
+	return func(l *instance) {
+		l.encoder.durationFormat = format
+	}
+}
+
+// WithBytesFormat sets the format of []byte values. Default is BytesBase64.
+func WithBytesFormat(format BytesFormat) Option {
+	return func(l *instance) {
+		l.encoder.bytesFormat = format
+	}
+}
+
+func (e *encoder) encodeTime(sw *StackWriter, t time.Time) (n int, err error) {
+	var buf [40]byte
+	return sw.WriteJSONString(bytesToString(appendRFC3339(buf[:0], t, 6, nil)))
+}
+
+func (e *encoder) encodeDuration(sw *StackWriter, d time.Duration) (n int, err error) {
+	var buf [32]byte
+	switch e.durationFormat {
+	case DurationMillis:
+		return sw.Write(string(strconv.AppendFloat(buf[:0], float64(d)/float64(time.Millisecond), 'f', -1, 64)))
+	case DurationNanos:
+		return sw.Write(string(strconv.AppendInt(buf[:0], int64(d), 10)))
+	default:
+		return sw.WriteJSONString(d.String())
+	}
+}
+
+func (e *encoder) encodeBytes(sw *StackWriter, b []byte) (n int, err error) {
+	if b == nil {
+		return sw.Write("null")
+	}
+	if e.bytesFormat == BytesString {
+		return sw.WriteJSONString(bytesToString(noescape_bytearray(&b)))
+	}
+
+	n, err = sw.Write("\"")
+	if err != nil {
+		return n, err
+	}
+
+	// Encode in chunks into a stack buffer to avoid heap allocations.
+	const chunkSize = 48
+	var buf [chunkSize * 2]byte
+	for len(b) > 0 {
+		chunk := b
+		if len(chunk) > chunkSize {
+			chunk = chunk[:chunkSize]
+		}
+		b = b[len(chunk):]
+
+		var encoded []byte
+		if e.bytesFormat == BytesHex {
+			encoded = buf[:hex.Encode(buf[:], chunk)]
+		} else {
+			encoded = buf[:base64.StdEncoding.EncodedLen(len(chunk))]
+			base64.StdEncoding.Encode(encoded, chunk)
+		}
+
+		nw, err := sw.Write(bytesToString(encoded))
+		n += nw
+		if err != nil {
+			return n, err
+		}
+	}
+
+	nw, err := sw.Write("\"")
+	n += nw
+	return n, err
+}